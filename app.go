@@ -1,10 +1,14 @@
 package main
 
 import (
+	"AlcoholTracker/server"
 	"AlcoholTracker/tracker"
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -12,27 +16,53 @@ import (
 
 // App struct
 type App struct {
-	ctx context.Context
+	ctx           context.Context
+	store         tracker.Store
+	snapshots     *tracker.Snapshotter // nil when the store can't be snapshotted
+	stopSnapshots context.CancelFunc
+	api           *server.Server // nil unless the REST API was started
 }
 
-// NewApp creates a new App application struct
-func NewApp() *App {
-	return &App{}
+// NewApp creates a new App application struct backed by the given store,
+// snapshotting it with snapshots and serving it with api unless they are nil
+func NewApp(store tracker.Store, snapshots *tracker.Snapshotter, api *server.Server) *App {
+	return &App{store: store, snapshots: snapshots, api: api}
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	DBerr := tracker.InitDB()
-	if DBerr != nil {
-		log.Fatal("Failed to initialize database:", DBerr)
+
+	// Judge the goal periods that ended while the app was closed by the targets they had
+	if err := tracker.RecordGoalHistory(a.store); err != nil {
+		runtime.LogError(ctx, "Error recording goal history: "+err.Error())
+	}
+
+	if a.snapshots != nil {
+		snapshotCtx, cancel := context.WithCancel(ctx)
+		a.stopSnapshots = cancel
+		go a.snapshots.Run(snapshotCtx)
 	}
 }
 
 // Shutdown
 func (a *App) Shutdown(ctx context.Context) {
-	tracker.CloseDB()
+	if a.stopSnapshots != nil {
+		a.stopSnapshots()
+	}
+	if a.api != nil {
+		// Let requests in flight finish before the store closes under them
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.api.Shutdown(stopCtx); err != nil {
+			log.Println("Failed to stop REST API:", err)
+		}
+	}
+	if err := a.store.Close(); err != nil {
+		log.Println("Failed to close database:", err)
+		return
+	}
 	log.Println("Database closed.")
 }
 
@@ -41,16 +71,33 @@ func (a *App) Greet(name string) string {
 	return fmt.Sprintf("Hello %s, It's show time!", name)
 }
 
-// Expose AddTrackerEntry to the frontend
-func (a *App) AddTrackerEntry(year int, month int, day int, category string, quantity int, cost float64) {
-	entry := tracker.DayData{
-		Alcohol:   category,
-		Quantity:  quantity,
-		Cost:      cost,
-		Timestamp: time.Now().Unix(),
+// volumeUnit resolves a unit code from the frontend, where "" means the display unit
+func (a *App) volumeUnit(code string) (tracker.VolumeUnit, error) {
+	if code == "" {
+		return tracker.GetDisplayUnit(a.store)
+	}
+	return tracker.LookupVolumeUnit(code)
+}
+
+// Expose AddTrackerEntry to the frontend; quantity is in unit ("" for the display unit),
+// cost is in major units (e.g. dollars) of currency ("" for the default currency)
+// and timeOfDay is the "HH:MM" the drink was had ("" for now, or noon on past dates)
+func (a *App) AddTrackerEntry(year int, month int, day int, category string, quantity float64, unit string, cost float64, currency string, timeOfDay string) {
+	volumeUnit, err := a.volumeUnit(unit)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error adding entry: "+err.Error())
+		return
 	}
 
-	err := tracker.AddTrackerEntry(year, month, day, category, entry)
+	_, err = tracker.LogEntry(a.store, tracker.EntryInput{
+		Date:     tracker.Date{Year: year, Month: month, Day: day},
+		Time:     timeOfDay,
+		Drink:    category,
+		Quantity: quantity,
+		Unit:     volumeUnit.Code,
+		Cost:     cost,
+		Currency: currency,
+	}, time.Now())
 	if err != nil {
 		runtime.LogError(a.ctx, "Error adding entry: "+err.Error())
 	}
@@ -58,15 +105,118 @@ func (a *App) AddTrackerEntry(year int, month int, day int, category string, qua
 
 // Expose GetEntriesByDate to the frontend
 func (a *App) GetEntriesByDate(year int, month int, day int, category string) []tracker.DayData {
-	entries, err := tracker.GetEntriesByDateList(year, month, day)
+	entries, err := tracker.GetEntriesByDateList(a.store, year, month, day)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching entries: "+err.Error())
+	}
+	return a.inDisplayUnit(entries)
+}
+
+// GetEntriesInRange returns the entries from one date to another, inclusive, in chronological order
+func (a *App) GetEntriesInRange(from tracker.Date, to tracker.Date) []tracker.Record {
+	records, err := tracker.GetEntriesInRange(a.store, from.Time(), to.Time())
 	if err != nil {
 		runtime.LogError(a.ctx, "Error fetching entries: "+err.Error())
 	}
-	return entries
+
+	unit, err := tracker.GetDisplayUnit(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching display unit: "+err.Error())
+	}
+	return tracker.RecordsInUnit(records, unit)
+}
+
+// GetWeekEntries returns the entries in the Monday-to-Sunday week holding the given date
+func (a *App) GetWeekEntries(year, month, day int) []tracker.Record {
+	monday, sunday := tracker.WeekOf(tracker.Date{Year: year, Month: month, Day: day})
+	return a.GetEntriesInRange(monday, sunday)
+}
+
+// inDisplayUnit converts entry quantities from mL to the display unit
+func (a *App) inDisplayUnit(entries []tracker.DayData) []tracker.DayData {
+	unit, err := tracker.GetDisplayUnit(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching display unit: "+err.Error())
+	}
+	return tracker.EntriesInUnit(entries, unit)
+}
+
+// GetVolumeUnits lists the units quantities can be entered and shown in
+func (a *App) GetVolumeUnits() []tracker.VolumeUnit {
+	return tracker.VolumeUnits
+}
+
+// GetDisplayUnit returns the unit App methods report quantities in
+func (a *App) GetDisplayUnit() tracker.VolumeUnit {
+	unit, err := tracker.GetDisplayUnit(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching display unit: "+err.Error())
+	}
+	return unit
+}
+
+// SetDisplayUnit selects the unit App methods report quantities in
+func (a *App) SetDisplayUnit(code string) bool {
+	err := tracker.SetDisplayUnit(a.store, code)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error setting display unit: "+err.Error())
+		return false
+	}
+	return true
 }
 
 func (a *App) GetAlcoholCategories() []string {
-	return tracker.GetAlcoholTypes()
+	categories, err := tracker.GetAlcoholTypes(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching catalog: "+err.Error())
+	}
+	return categories
+}
+
+// GetDrinkCatalog returns every catalog drink, including archived ones
+func (a *App) GetDrinkCatalog() []tracker.Drink {
+	drinks, err := a.store.GetDrinks()
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching catalog: "+err.Error())
+	}
+	return drinks
+}
+
+// SaveDrink adds a drink to the catalog or edits the one with the same name
+func (a *App) SaveDrink(name string, abv float64, servingML float64, color string) bool {
+	drink := tracker.Drink{
+		Name:      name,
+		ABV:       abv,
+		ServingML: servingML,
+		Color:     color,
+	}
+
+	// The form has no archive toggle, so an edit keeps the drink where it was
+	catalog, err := tracker.LoadCatalog(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error loading drink catalog: "+err.Error())
+		return false
+	}
+	if existing, exists := catalog[strings.TrimSpace(name)]; exists {
+		drink.Archived = existing.Archived
+	}
+
+	err = tracker.SaveDrink(a.store, drink)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving drink: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// ArchiveDrink hides a drink from the pickers, or restores it when archived is false
+func (a *App) ArchiveDrink(name string, archived bool) bool {
+	err := tracker.ArchiveDrink(a.store, name, archived)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error archiving drink: "+err.Error())
+		return false
+	}
+	return true
 }
 
 func (a *App) ValidateFormDate(year, month, day int) bool {
@@ -74,62 +224,292 @@ func (a *App) ValidateFormDate(year, month, day int) bool {
 	return err == nil
 }
 
-func (a *App) GetDrinks(year, month, day int) string {
-	categories := []string{"empty", "low", "moderate", "heavy", "binge", "excessive"}
-	drinks, err := tracker.GetTotalDrinksOnDay(year, month, day)
+// GetDayTier classifies a day into a consumption tier, with its color and drink count
+func (a *App) GetDayTier(year, month, day int) tracker.TierResult {
+	result, err := tracker.ClassifyDay(a.store, year, month, day)
 	if err != nil {
-		fmt.Println(drinks, err)
-		return categories[0]
+		runtime.LogError(a.ctx, "Error classifying day: "+err.Error())
 	}
+	return result
+}
 
-	switch {
-	case drinks == -1:
-		return categories[0]
-	case drinks >= 0 && drinks < 1:
-		return categories[1]
-	case drinks >= 1 && drinks < 2:
-		return categories[2]
-	case drinks >= 2 && drinks < 3:
-		return categories[3]
-	case drinks >= 3 && drinks < 5:
-		return categories[4]
-	case drinks >= 5:
-		return categories[5]
-	default:
-		return categories[0]
+// GetTiers returns the consumption tiers; boundaries are in grams of alcohol
+func (a *App) GetTiers() []tracker.Tier {
+	tiers, err := tracker.GetTiers(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching tiers: "+err.Error())
 	}
+	return tiers
 }
 
-func (a *App) GetDrinkTagColor(year, month, day int) int {
-	// categories := []string{"gray", "#60aa9b", "#43766c", "#ffdf60", "#fa8072", "#ed4d09"}
-	drinks, err := tracker.GetTotalDrinksOnDay(year, month, day)
+// SaveTiers replaces the consumption tiers
+func (a *App) SaveTiers(tiers []tracker.Tier) bool {
+	err := tracker.SaveTiers(a.store, tiers)
 	if err != nil {
-		fmt.Println(drinks, err)
-		return 0
+		runtime.LogError(a.ctx, "Error saving tiers: "+err.Error())
+		return false
 	}
+	return true
+}
 
-	switch {
-	case drinks == -1:
-		return 0
-	case drinks >= 0 && drinks < 1:
-		return 1
-	case drinks >= 1 && drinks < 2:
-		return 2
-	case drinks >= 2 && drinks < 3:
-		return 3
-	case drinks >= 3 && drinks < 5:
-		return 4
-	case drinks >= 5:
-		return 5
-	default:
-		return 0
+// ResetTiers restores the default consumption tiers
+func (a *App) ResetTiers() bool {
+	err := tracker.ResetTiers(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error resetting tiers: "+err.Error())
+		return false
 	}
+	return true
+}
+
+// GetPeriodStats totals the week, month or year ("week", "month" or "year") holding a date
+func (a *App) GetPeriodStats(period string, year, month, day int) tracker.PeriodStats {
+	stats, err := tracker.GetPeriodStats(a.store, period, tracker.Date{Year: year, Month: month, Day: day})
+	if err != nil {
+		runtime.LogError(a.ctx, "Error calculating stats: "+err.Error())
+	}
+	return stats
+}
+
+// GetStatsInRange totals the drinking days from one date to another, inclusive
+func (a *App) GetStatsInRange(from tracker.Date, to tracker.Date) tracker.PeriodStats {
+	stats, err := tracker.GetStats(a.store, from, to)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error calculating stats: "+err.Error())
+	}
+	return stats
+}
+
+// GetStreaks returns the current and longest sober and drinking streaks with their history
+func (a *App) GetStreaks() tracker.StreakSummary {
+	streaks, err := tracker.GetStreaks(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error calculating streaks: "+err.Error())
+	}
+	return streaks
+}
+
+// GetGoals returns the saved consumption and spending goals
+func (a *App) GetGoals() []tracker.Goal {
+	goals, err := tracker.GetGoals(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching goals: "+err.Error())
+	}
+	return goals
+}
+
+// SaveGoal adds a goal, or replaces the one with the same ID, and returns it as saved
+func (a *App) SaveGoal(goal tracker.Goal) (tracker.Goal, error) {
+	saved, err := tracker.SaveGoal(a.store, goal)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving goal: "+err.Error())
+	}
+	return saved, err
+}
+
+// DeleteGoal removes a goal and its history
+func (a *App) DeleteGoal(id string) bool {
+	err := tracker.DeleteGoal(a.store, id)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error deleting goal: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetGoalStatus returns each goal's progress in the current period and its past results
+func (a *App) GetGoalStatus() []tracker.GoalStatus {
+	statuses, err := tracker.GetGoalStatuses(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error evaluating goals: "+err.Error())
+	}
+	return statuses
+}
+
+// GetSpendingReport breaks down the spend in the week, month or year ("week", "month" or "year") holding a date
+func (a *App) GetSpendingReport(period string, year, month, day int) tracker.SpendingReport {
+	report, err := tracker.GetPeriodSpending(a.store, period, tracker.Date{Year: year, Month: month, Day: day})
+	if err != nil {
+		runtime.LogError(a.ctx, "Error calculating spending: "+err.Error())
+	}
+	return report
+}
+
+// GetSpendingInRange breaks down the spend from one date to another, inclusive
+func (a *App) GetSpendingInRange(from tracker.Date, to tracker.Date) tracker.SpendingReport {
+	report, err := tracker.GetSpendingReport(a.store, from, to)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error calculating spending: "+err.Error())
+	}
+	return report
+}
+
+// GetMonthlyBudget returns the monthly spending budget in minor units; the amount is 0 if none is set
+func (a *App) GetMonthlyBudget() tracker.Money {
+	budget, err := tracker.GetMonthlyBudget(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching budget: "+err.Error())
+	}
+	return budget
+}
+
+// SetMonthlyBudget sets the monthly spending budget in major units of currency
+// ("" for the reporting currency); 0 removes it
+func (a *App) SetMonthlyBudget(budget float64, currency string) bool {
+	budgetCurrency, err := tracker.LookupCurrency(currency)
+	if currency == "" {
+		budgetCurrency, err = tracker.GetReportingCurrency(a.store)
+	}
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving budget: "+err.Error())
+		return false
+	}
+
+	err = tracker.SetMonthlyBudget(a.store, tracker.Money{Amount: budgetCurrency.ToMinor(budget), Currency: budgetCurrency.Code})
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving budget: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetBudgetStatus compares a month's spend with the monthly budget
+func (a *App) GetBudgetStatus(year, month int) tracker.BudgetStatus {
+	status, err := tracker.GetBudgetStatus(a.store, year, month)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error calculating budget: "+err.Error())
+	}
+	return status
+}
+
+// GetCurrencies returns the currencies costs can be logged in
+func (a *App) GetCurrencies() []tracker.Currency {
+	return tracker.Currencies
+}
+
+// GetDefaultCurrency returns the currency new entries are logged in
+func (a *App) GetDefaultCurrency() tracker.Currency {
+	currency, err := tracker.GetDefaultCurrency(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching default currency: "+err.Error())
+	}
+	return currency
+}
+
+// SetDefaultCurrency selects the currency new entries are logged in
+func (a *App) SetDefaultCurrency(code string) bool {
+	err := tracker.SetDefaultCurrency(a.store, code)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving default currency: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetReportingCurrency returns the currency spending totals are shown in
+func (a *App) GetReportingCurrency() tracker.Currency {
+	currency, err := tracker.GetReportingCurrency(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching reporting currency: "+err.Error())
+	}
+	return currency
+}
+
+// SetReportingCurrency selects the currency spending totals are shown in; "" follows the default currency
+func (a *App) SetReportingCurrency(code string) bool {
+	err := tracker.SetReportingCurrency(a.store, code)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving reporting currency: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetExchangeRates returns the exchange-rate table
+func (a *App) GetExchangeRates() []tracker.ExchangeRate {
+	rates, err := tracker.GetExchangeRates(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching exchange rates: "+err.Error())
+	}
+	return rates
+}
+
+// SetExchangeRate sets how many units of to one unit of from is worth
+func (a *App) SetExchangeRate(from, to string, rate float64) bool {
+	err := tracker.SetExchangeRate(a.store, from, to, rate)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving exchange rate: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// DeleteExchangeRate removes the rate between two currencies
+func (a *App) DeleteExchangeRate(from, to string) bool {
+	err := tracker.DeleteExchangeRate(a.store, from, to)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error deleting exchange rate: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetBodyProfile returns the weight and Widmark factors used for BAC estimates
+func (a *App) GetBodyProfile() tracker.BodyProfile {
+	profile, err := tracker.GetBodyProfile(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching body profile: "+err.Error())
+	}
+	return profile
+}
+
+// SaveBodyProfile stores the weight and Widmark factors used for BAC estimates
+func (a *App) SaveBodyProfile(profile tracker.BodyProfile) bool {
+	err := tracker.SaveBodyProfile(a.store, profile)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving body profile: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetBAC estimates the current blood alcohol concentration and the time until sober
+func (a *App) GetBAC() (tracker.BACEstimate, error) {
+	estimate, err := tracker.EstimateBAC(a.store, time.Now())
+	if err != nil {
+		runtime.LogError(a.ctx, "Error estimating BAC: "+err.Error())
+	}
+	return estimate, err
+}
+
+// GetStandardDrinkOptions lists the built-in standard drink definitions
+func (a *App) GetStandardDrinkOptions() []tracker.StandardDrink {
+	return tracker.StandardDrinks
+}
+
+// GetStandardDrink returns the definition used for drink counts
+func (a *App) GetStandardDrink() tracker.StandardDrink {
+	def, err := tracker.GetStandardDrink(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching standard drink: "+err.Error())
+	}
+	return def
+}
+
+// SetStandardDrink selects a definition by code; customGrams is only used with the CUSTOM code
+func (a *App) SetStandardDrink(code string, customGrams float64) bool {
+	err := tracker.SetStandardDrink(a.store, code, customGrams)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error setting standard drink: "+err.Error())
+		return false
+	}
+	return true
 }
 
 func (a *App) GetDrinkCount(year, month, day int) float64 {
-	drinks, err := tracker.GetTotalDrinksOnDay(year, month, day)
+	drinks, err := tracker.GetTotalDrinksOnDay(a.store, year, month, day)
 	if err != nil {
-		fmt.Println(drinks, err)
+		runtime.LogError(a.ctx, "Error counting drinks: "+err.Error())
 		return 0
 	}
 
@@ -142,43 +522,300 @@ func (a *App) GetDrinkCount(year, month, day int) float64 {
 }
 
 func (a *App) GetEntriesOnDate(year, month, day int) (map[string][]tracker.DayData, error) {
-	entries, err := tracker.GetEntriesByDate(year, month, day)
+	entries, err := a.store.GetEntriesByDate(year, month, day)
 	if err != nil {
-		fmt.Println(err)
+		runtime.LogError(a.ctx, "Error fetching entries: "+err.Error())
+	}
+	for category, categoryEntries := range entries {
+		entries[category] = a.inDisplayUnit(categoryEntries)
 	}
 	return entries, err
 }
 
+// GetCurrentDrinkingDay returns the drinking day in progress, which lags the
+// calendar date until the rollover hour
+func (a *App) GetCurrentDrinkingDay() tracker.Date {
+	today, err := tracker.GetCurrentDrinkingDay(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching drinking day: "+err.Error())
+	}
+	return today
+}
+
+// GetDayRolloverHour returns the hour at which a new drinking day starts
+func (a *App) GetDayRolloverHour() int {
+	hour, err := tracker.GetDayRolloverHour(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching rollover hour: "+err.Error())
+	}
+	return hour
+}
+
+// SetDayRolloverHour sets the hour at which a new drinking day starts
+func (a *App) SetDayRolloverHour(hour int) bool {
+	err := tracker.SetDayRolloverHour(a.store, hour)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error setting rollover hour: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetTimezone returns the IANA time zone dates are calculated in ("" for the system zone)
+func (a *App) GetTimezone() string {
+	name, err := tracker.GetTimezone(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching time zone: "+err.Error())
+	}
+	return name
+}
+
+// SetTimezone sets the IANA time zone dates are calculated in, e.g. "America/New_York"
+func (a *App) SetTimezone(name string) bool {
+	err := tracker.SetTimezone(a.store, name)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error setting time zone: "+err.Error())
+		return false
+	}
+	return true
+}
+
 func (a *App) GetDaysSinceLastDrink() int {
-	days, err := tracker.GetDaysSinceLastEntry()
+	days, err := tracker.GetDaysSinceLastEntry(a.store)
 	if err != nil {
-		fmt.Println(days, err)
+		runtime.LogError(a.ctx, "Error calculating days since last drink: "+err.Error())
 	}
-	fmt.Println(days)
 	return days
 }
 
-func (a *App) DeleteDrink(year, month, day int, alcohol string, timestamp int64) bool {
-	err := tracker.DeleteEntry(year, month, day, alcohol, timestamp)
+func (a *App) GetDrink(year, month, day int, id string) (tracker.DayData, error) {
+	entry, err := a.store.GetEntry(year, month, day, id)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching entry: "+err.Error())
+		return entry, err
+	}
+	return a.inDisplayUnit([]tracker.DayData{entry})[0], nil
+}
+
+func (a *App) DeleteDrink(year, month, day int, id string) bool {
+	err := a.store.DeleteEntry(year, month, day, id)
 	if err != nil {
-		fmt.Println(err)
+		runtime.LogError(a.ctx, "Error deleting entry: "+err.Error())
 		return false
 	}
 
 	return true
 }
 
-// Expose AddTrackerEntry to the frontend
-func (a *App) AddTrackerEntryUpdate(year int, month int, day int, category string, quantity int, cost float64, timestamp int64) {
-	entry := tracker.DayData{
-		Alcohol:   category,
-		Quantity:  quantity,
-		Cost:      cost,
-		Timestamp: timestamp,
+// UpdateDrink edits an entry in one transaction, moving it to the new date and category if they changed
+// cost is in major units of currency ("" keeps the entry's currency) and timeOfDay is
+// the new "HH:MM" the drink was had, or "" to keep its current time
+func (a *App) UpdateDrink(year, month, day int, id string, newYear, newMonth, newDay int, category string, quantity float64, unit string, cost float64, currency string, timeOfDay string) bool {
+	volumeUnit, err := a.volumeUnit(unit)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error updating entry: "+err.Error())
+		return false
 	}
 
-	err := tracker.AddTrackerEntry(year, month, day, category, entry)
+	patch := tracker.EntryPatch{
+		Date:     &tracker.Date{Year: newYear, Month: newMonth, Day: newDay},
+		Drink:    &category,
+		Quantity: &quantity,
+		Unit:     volumeUnit.Code,
+		Cost:     &cost,
+	}
+	// An empty currency keeps the one the entry was logged in, and an empty time its time
+	if currency != "" {
+		patch.Currency = &currency
+	}
+	if timeOfDay != "" {
+		patch.Time = &timeOfDay
+	}
+
+	_, err = tracker.EditEntry(a.store, tracker.Date{Year: year, Month: month, Day: day}, id, patch)
 	if err != nil {
-		runtime.LogError(a.ctx, "Error adding entry: "+err.Error())
+		runtime.LogError(a.ctx, "Error updating entry: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// csvFilter limits the file dialogs to CSV files
+var csvFilter = []runtime.FileFilter{{DisplayName: "CSV files (*.csv)", Pattern: "*.csv"}}
+
+// ExportCSV asks where to save the drink history and writes it there as CSV.
+// It returns the chosen path, or "" if the dialog was cancelled.
+func (a *App) ExportCSV() (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export drink history",
+		DefaultFilename: "alcoholtracker-" + time.Now().Format("2006-01-02") + ".csv",
+		Filters:         csvFilter,
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error exporting CSV: "+err.Error())
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := tracker.ExportCSV(a.store, file); err != nil {
+		runtime.LogError(a.ctx, "Error exporting CSV: "+err.Error())
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// ImportCSV asks for a CSV file and adds its rows, skipping ones already stored.
+// Rows with errors are listed in the result; nothing is imported if the dialog was cancelled.
+func (a *App) ImportCSV() (tracker.ImportResult, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import drink history",
+		Filters: csvFilter,
+	})
+	if err != nil || path == "" {
+		return tracker.ImportResult{Errors: []tracker.RowError{}}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error importing CSV: "+err.Error())
+		return tracker.ImportResult{Errors: []tracker.RowError{}}, err
+	}
+	defer file.Close()
+
+	result, err := tracker.ImportCSV(a.store, file)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error importing CSV: "+err.Error())
+	}
+	return result, err
+}
+
+// jsonFilter limits the file dialogs to JSON files
+var jsonFilter = []runtime.FileFilter{{DisplayName: "JSON files (*.json)", Pattern: "*.json"}}
+
+// BackupToFile asks where to save a JSON backup of everything in the database and writes it.
+// It returns the chosen path, or "" if the dialog was cancelled.
+func (a *App) BackupToFile() (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Back up database",
+		DefaultFilename: "alcoholtracker-backup-" + time.Now().Format("2006-01-02") + ".json",
+		Filters:         jsonFilter,
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error writing backup: "+err.Error())
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := tracker.WriteBackup(a.store, file); err != nil {
+		runtime.LogError(a.ctx, "Error writing backup: "+err.Error())
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// RestoreFromFile asks for a JSON backup and restores it in "merge" or "replace" mode
+func (a *App) RestoreFromFile(mode string) (tracker.RestoreResult, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Restore database",
+		Filters: jsonFilter,
+	})
+	if err != nil || path == "" {
+		return tracker.RestoreResult{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error restoring backup: "+err.Error())
+		return tracker.RestoreResult{}, err
+	}
+	defer file.Close()
+
+	result, err := tracker.RestoreBackupFile(a.store, file, mode)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error restoring backup: "+err.Error())
+	}
+	return result, err
+}
+
+// errNoSnapshots is returned when the store has no file to snapshot
+var errNoSnapshots = errors.New("snapshots are not available for this database")
+
+// ListSnapshots returns the automatic snapshots of the database, newest first
+func (a *App) ListSnapshots() []tracker.Snapshot {
+	if a.snapshots == nil {
+		return []tracker.Snapshot{}
+	}
+
+	snapshots, err := a.snapshots.List()
+	if err != nil {
+		runtime.LogError(a.ctx, "Error listing snapshots: "+err.Error())
+	}
+	return snapshots
+}
+
+// TakeSnapshot snapshots the database now
+func (a *App) TakeSnapshot() (tracker.Snapshot, error) {
+	if a.snapshots == nil {
+		return tracker.Snapshot{}, errNoSnapshots
+	}
+
+	snapshot, err := a.snapshots.Snapshot()
+	if err != nil {
+		runtime.LogError(a.ctx, "Error taking snapshot: "+err.Error())
+	}
+	return snapshot, err
+}
+
+// RestoreSnapshot replaces the database contents with the named snapshot,
+// snapshotting the current contents first
+func (a *App) RestoreSnapshot(name string) bool {
+	if a.snapshots == nil {
+		runtime.LogError(a.ctx, "Error restoring snapshot: "+errNoSnapshots.Error())
+		return false
+	}
+
+	err := a.snapshots.Restore(name)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error restoring snapshot: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetSnapshotPolicy returns how often snapshots are taken and how many are kept
+func (a *App) GetSnapshotPolicy() tracker.SnapshotPolicy {
+	policy, err := tracker.GetSnapshotPolicy(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching snapshot policy: "+err.Error())
+	}
+	return policy
+}
+
+// SaveSnapshotPolicy changes the snapshot schedule and retention from the next start
+func (a *App) SaveSnapshotPolicy(policy tracker.SnapshotPolicy) bool {
+	err := tracker.SaveSnapshotPolicy(a.store, policy)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error saving snapshot policy: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// GetAPIToken returns the token REST API clients send, for showing while -serve runs
+func (a *App) GetAPIToken() string {
+	token, err := tracker.GetAPIToken(a.store)
+	if err != nil {
+		runtime.LogError(a.ctx, "Error fetching API token: "+err.Error())
 	}
+	return token
 }
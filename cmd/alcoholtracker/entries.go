@@ -0,0 +1,225 @@
+package main
+
+import (
+	"AlcoholTracker/tracker"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// runAdd logs a drink, by default now
+func runAdd(c *cli, args []string) error {
+	fs := c.flagSet()
+	date := fs.String("date", "", "drinking day, as YYYY-MM-DD, today or yesterday (default the date now)")
+	timeOfDay := fs.String("time", "", "HH:MM the drink was had (default now, or noon on past dates)")
+	unit := fs.String("unit", "", "unit of the quantity, e.g. ml, cl, floz_us or pint_uk (default the display unit)")
+	cost := fs.Float64("cost", 0, "what the drink cost, e.g. 4.50")
+	currency := fs.String("currency", "", "currency of the cost (default the default currency)")
+
+	positional, err := c.parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	quantity, err := strconv.ParseFloat(positional[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid quantity '%s': expected a number above 0", positional[1])
+	}
+
+	volumeUnit, err := c.volumeUnit(*unit)
+	if err != nil {
+		return err
+	}
+
+	// Without a date, LogEntry stores the drink under the date now
+	var day tracker.Date
+	if *date != "" {
+		if day, err = tracker.ParseDate(c.store, *date); err != nil {
+			return err
+		}
+	}
+
+	record, err := tracker.LogEntry(c.store, tracker.EntryInput{
+		Date:     day,
+		Time:     *timeOfDay,
+		Drink:    positional[0],
+		Quantity: quantity,
+		Unit:     volumeUnit.Code,
+		Cost:     *cost,
+		Currency: *currency,
+	}, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.printRecord("Added", record)
+}
+
+// runList prints the drinks stored between two dates, a week up to today by default
+func runList(c *cli, args []string) error {
+	fs := c.flagSet()
+	from := fs.String("from", "", "first date, as YYYY-MM-DD (default 6 days before --to)")
+	to := fs.String("to", "today", "last date, as YYYY-MM-DD, today or yesterday")
+	unit := fs.String("unit", "", "unit to show volumes in (default the display unit; JSON is always in mL)")
+
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	toDate, err := tracker.ParseDate(c.store, *to)
+	if err != nil {
+		return err
+	}
+	fromDate := tracker.DateOf(toDate.Time().AddDate(0, 0, -6))
+	if *from != "" {
+		if fromDate, err = tracker.ParseDate(c.store, *from); err != nil {
+			return err
+		}
+	}
+
+	records, err := tracker.GetEntriesInRange(c.store, fromDate.Time(), toDate.Time())
+	if err != nil {
+		return err
+	}
+
+	volumeUnit, err := c.volumeUnit(*unit)
+	if err != nil {
+		return err
+	}
+
+	def, err := tracker.GetStandardDrink(c.store)
+	if err != nil {
+		return err
+	}
+
+	return c.print(records, func(w io.Writer) {
+		if len(records) == 0 {
+			fmt.Fprintf(w, "No drinks logged from %s to %s\n", fromDate, toDate)
+			return
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "DATE\tTIME\tDRINK\tVOLUME\tABV\tDRINKS\tCOST\tID\n")
+		for _, record := range records {
+			entry := record.Entry
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%.4g %s\t%g%%\t%.2f\t%s\t%s\n",
+				record.Date,
+				entry.ConsumedAt.Format("15:04"),
+				record.Category,
+				volumeUnit.FromML(entry.Quantity), volumeUnit.Symbol,
+				entry.ABV,
+				tracker.StandardDrinksFromGrams(entry.AlcoholGrams, def),
+				formatMoney(entry.Cost, entry.Currency),
+				entry.ID)
+		}
+		tw.Flush()
+	})
+}
+
+// runDelete removes a drink by ID
+func runDelete(c *cli, args []string) error {
+	fs := c.flagSet()
+
+	positional, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	record, err := tracker.FindRecord(c.store, positional[0])
+	if err != nil {
+		return err
+	}
+
+	err = c.store.DeleteEntry(record.Date.Year, record.Date.Month, record.Date.Day, record.Entry.ID)
+	if err != nil {
+		return err
+	}
+	return c.printRecord("Deleted", record)
+}
+
+// runEdit changes the fields of a drink given by flags, leaving the others as they are
+func runEdit(c *cli, args []string) error {
+	fs := c.flagSet()
+	drink := fs.String("drink", "", "drink name; its ABV is taken from the catalog")
+	quantity := fs.Float64("quantity", 0, "quantity in --unit")
+	unit := fs.String("unit", "", "unit of --quantity (default the display unit)")
+	cost := fs.Float64("cost", 0, "what the drink cost")
+	currency := fs.String("currency", "", "currency of the cost; without --cost the amount is kept")
+	date := fs.String("date", "", "move the drink to this date, as YYYY-MM-DD, today or yesterday")
+	timeOfDay := fs.String("time", "", "HH:MM the drink was had")
+
+	positional, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["drink"] && !set["quantity"] && !set["cost"] && !set["currency"] && !set["date"] && !set["time"] {
+		return errors.New("nothing to change: pass at least one of --drink, --quantity, --cost, --currency, --date or --time")
+	}
+
+	record, err := tracker.FindRecord(c.store, positional[0])
+	if err != nil {
+		return err
+	}
+
+	patch := tracker.EntryPatch{}
+	if set["drink"] {
+		patch.Drink = drink
+	}
+	if set["quantity"] {
+		volumeUnit, err := c.volumeUnit(*unit)
+		if err != nil {
+			return err
+		}
+		patch.Quantity, patch.Unit = quantity, volumeUnit.Code
+	}
+	if set["cost"] {
+		patch.Cost = cost
+	}
+	if set["currency"] {
+		patch.Currency = currency
+	}
+	if set["date"] {
+		newDate, err := tracker.ParseDate(c.store, *date)
+		if err != nil {
+			return err
+		}
+		patch.Date = &newDate
+	}
+	if set["time"] {
+		patch.Time = timeOfDay
+	}
+
+	record, err = tracker.EditEntry(c.store, record.Date, record.Entry.ID, patch)
+	if err != nil {
+		return err
+	}
+	return c.printRecord("Updated", record)
+}
+
+// printRecord reports a single entry that was added, changed or removed
+func (c *cli) printRecord(action string, record tracker.Record) error {
+	def, err := tracker.GetStandardDrink(c.store)
+	if err != nil {
+		return err
+	}
+
+	volumeUnit, err := tracker.GetDisplayUnit(c.store)
+	if err != nil {
+		return err
+	}
+
+	return c.print(record, func(w io.Writer) {
+		entry := record.Entry
+		fmt.Fprintf(w, "%s %.4g %s of %s (%g%%, %.2f drinks, %s) on %s at %s\nID: %s\n",
+			action, volumeUnit.FromML(entry.Quantity), volumeUnit.Symbol, record.Category, entry.ABV,
+			tracker.StandardDrinksFromGrams(entry.AlcoholGrams, def),
+			formatMoney(entry.Cost, entry.Currency),
+			record.Date, entry.ConsumedAt.Format("15:04"), entry.ID)
+	})
+}
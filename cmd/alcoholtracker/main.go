@@ -0,0 +1,216 @@
+// Command alcoholtracker logs and queries drinks from a terminal, using the
+// same database file as the desktop app.
+package main
+
+import (
+	"AlcoholTracker/tracker"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// command is one subcommand of the CLI
+type command struct {
+	name    string
+	args    string // Arguments shown after the name in usage
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{"add", "[flags] <drink> <quantity>", "log a drink", runAdd},
+	{"list", "[--from YYYY-MM-DD] [--to YYYY-MM-DD]", "list the drinks logged between two dates", runList},
+	{"delete", "<id>", "delete a drink", runDelete},
+	{"edit", "[flags] <id>", "change a logged drink", runEdit},
+	{"stats", "[--period week|month|year] [--date YYYY-MM-DD] | [--from ... --to ...]", "summarise a period", runStats},
+	{"export", "[-o file.csv]", "write the drink history as CSV", runExport},
+	{"import", "<file.csv | ->", "add the drinks in a CSV file", runImport},
+	{"serve", "[--addr host:port] [--print-token]", "serve the REST API until interrupted", runServe},
+	{"token", "[--reset]", "print the REST API token", runToken},
+}
+
+// errUsage is returned once a usage message has been printed
+var errUsage = errors.New("usage")
+
+// cli holds the options shared by every command and the store they open
+type cli struct {
+	cmd     command
+	dbPath  string
+	json    bool
+	verbose bool
+	store   tracker.Store
+	out     io.Writer
+	errOut  io.Writer       // Usage messages and logs
+	ctx     context.Context // Cancelled to stop long-running commands such as serve
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command named by the first argument and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	log.SetFlags(0)
+	log.SetPrefix("alcoholtracker: ")
+	log.SetOutput(stderr)
+
+	if len(args) < 1 {
+		usage(stderr)
+		return 2
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage(stdout)
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		c := &cli{cmd: cmd, out: stdout, errOut: stderr, ctx: ctx}
+		err := cmd.run(c, args[1:])
+		if c.store != nil {
+			if closeErr := c.store.Close(); err == nil {
+				err = closeErr
+			}
+		}
+
+		switch {
+		case err == nil:
+		case errors.Is(err, flag.ErrHelp):
+		case errors.Is(err, errUsage):
+			return 2
+		default:
+			log.SetOutput(stderr)
+			log.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "unknown command '%s'\n\n", name)
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: alcoholtracker <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Every command accepts -db to pick the database, -json for machine-readable")
+	fmt.Fprintln(w, "output and -v to log what the database is doing. Run 'alcoholtracker <command> -h'")
+	fmt.Fprintln(w, "for the flags of a command.")
+}
+
+// flagSet creates the flags of the command being run, including the shared ones
+func (c *cli) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.cmd.name, flag.ContinueOnError)
+	fs.SetOutput(c.errOut)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: alcoholtracker %s %s\n\nFlags:\n", c.cmd.name, c.cmd.args)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&c.dbPath, "db", "", "path to the tracker database (overrides $"+tracker.DBPathEnv+" and the config file)")
+	fs.BoolVar(&c.json, "json", false, "print the result as JSON")
+	fs.BoolVar(&c.verbose, "v", false, "log database activity to stderr")
+	return fs
+}
+
+// parse reads flags wherever they appear among the arguments, checks the number
+// of positional arguments and opens the database. It returns the positional arguments.
+func (c *cli) parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) < minArgs || len(positional) > maxArgs {
+		fmt.Fprintf(fs.Output(), "%s: expected %s\n", fs.Name(), argCount(minArgs, maxArgs))
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	return positional, c.open()
+}
+
+func argCount(minArgs, maxArgs int) string {
+	switch {
+	case maxArgs == 0:
+		return "no arguments"
+	case minArgs == maxArgs && maxArgs == 1:
+		return "1 argument"
+	case minArgs == maxArgs:
+		return fmt.Sprintf("%d arguments", maxArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", minArgs, maxArgs)
+}
+
+// open opens the database the desktop app uses, or the one picked with -db
+func (c *cli) open() error {
+	if !c.verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	path, err := tracker.ResolveDBPath(c.dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database path: %v", err)
+	}
+
+	store, err := tracker.InitDB(path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	c.store = store
+	return nil
+}
+
+// print writes v as JSON with -json, and calls text to write it for people otherwise
+func (c *cli) print(v interface{}, text func(w io.Writer)) error {
+	if c.json {
+		encoder := json.NewEncoder(c.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	text(c.out)
+	return nil
+}
+
+// volumeUnit resolves a unit code, where "" means the display unit
+func (c *cli) volumeUnit(code string) (tracker.VolumeUnit, error) {
+	if code == "" {
+		return tracker.GetDisplayUnit(c.store)
+	}
+	return tracker.LookupVolumeUnit(code)
+}
+
+// formatMoney renders an amount in minor units of a currency code
+func formatMoney(amount int64, code string) string {
+	currency, err := tracker.LookupCurrency(code)
+	if err != nil {
+		return fmt.Sprintf("%d %s", amount, code)
+	}
+	return currency.Format(amount)
+}
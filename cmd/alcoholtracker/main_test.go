@@ -0,0 +1,95 @@
+package main
+
+import (
+	"AlcoholTracker/tracker"
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// runDB runs the CLI against the database at db and returns the exit code and output
+func runDB(t *testing.T, db string, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append(args, "-db", db), &stdout, &stderr)
+	if code != 0 {
+		return code, stderr.String()
+	}
+	return code, stdout.String()
+}
+
+// runJSON runs a command that must succeed with -json and decodes its output into v
+func runJSON(t *testing.T, db string, v interface{}, args ...string) {
+	t.Helper()
+	code, out := runDB(t, db, append(args, "-json")...)
+	if code != 0 {
+		t.Fatalf("%v exited with %d: %s", args, code, out)
+	}
+	if err := json.Unmarshal([]byte(out), v); err != nil {
+		t.Fatalf("%v printed invalid JSON: %v\n%s", args, err, out)
+	}
+}
+
+func TestAddListEditDelete(t *testing.T) {
+	db := filepath.Join(t.TempDir(), tracker.DefaultDBFile)
+
+	var added tracker.Record
+	runJSON(t, db, &added, "add", "Beer", "500", "--date", "2024-03-05", "--time", "20:15", "--cost", "6.50")
+	entry := added.Entry
+	if added.Date != (tracker.Date{Year: 2024, Month: 3, Day: 5}) || added.Category != "Beer" {
+		t.Errorf("added %s on %s, want Beer on 2024-03-05", added.Category, added.Date)
+	}
+	if entry.Quantity != 500 || entry.Cost != 650 || entry.ConsumedAt.Format("15:04") != "20:15" {
+		t.Errorf("added %+v, want 500 mL costing 650 at 20:15", entry)
+	}
+
+	var listed []tracker.Record
+	runJSON(t, db, &listed, "list", "--from", "2024-03-01", "--to", "2024-03-31")
+	if len(listed) != 1 || listed[0].Entry.ID != entry.ID {
+		t.Fatalf("list = %+v, want the added entry", listed)
+	}
+
+	var edited tracker.Record
+	runJSON(t, db, &edited, "edit", entry.ID, "--quantity", "330", "--date", "2024-03-06")
+	if edited.Date.Day != 6 || edited.Entry.Quantity != 330 || edited.Entry.Cost != 650 {
+		t.Errorf("edited %+v on %s, want 330 mL on the 6th with the cost kept", edited.Entry, edited.Date)
+	}
+	runJSON(t, db, &listed, "list", "--from", "2024-03-05", "--to", "2024-03-05")
+	if len(listed) != 0 {
+		t.Errorf("the 5th still lists %d entries after the move", len(listed))
+	}
+
+	if code, out := runDB(t, db, "delete", entry.ID); code != 0 {
+		t.Fatalf("delete exited with %d: %s", code, out)
+	}
+	runJSON(t, db, &listed, "list", "--from", "2024-03-01", "--to", "2024-03-31")
+	if len(listed) != 0 {
+		t.Errorf("list = %+v after deleting, want nothing", listed)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	db := filepath.Join(t.TempDir(), tracker.DefaultDBFile)
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"add", "Beer", "lots"}, 1},
+		{[]string{"add", "Moonshine", "500"}, 1},
+		{[]string{"add", "Beer"}, 2},
+		{[]string{"add", "Beer", "500", "--bogus"}, 2},
+		{[]string{"delete", "missing"}, 1},
+		{[]string{"edit", "missing"}, 1},
+		{[]string{"list", "--to", "2024-02-30"}, 1},
+		{[]string{"frobnicate"}, 2},
+		{[]string{"list", "-h"}, 0},
+	}
+	for _, test := range tests {
+		if code, _ := runDB(t, db, test.args...); code != test.want {
+			t.Errorf("%v exited with %d, want %d", test.args, code, test.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"AlcoholTracker/tracker"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runStats summarises the week, month or year holding a date, or a custom range
+func runStats(c *cli, args []string) error {
+	fs := c.flagSet()
+	period := fs.String("period", tracker.PeriodWeek, "week, month or year")
+	date := fs.String("date", "today", "a date in the period, as YYYY-MM-DD, today or yesterday")
+	from := fs.String("from", "", "first date of a custom range (needs --to)")
+	to := fs.String("to", "", "last date of a custom range (needs --from)")
+
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	var stats tracker.PeriodStats
+	switch {
+	case *from != "" && *to != "":
+		fromDate, err := tracker.ParseDate(c.store, *from)
+		if err != nil {
+			return err
+		}
+		toDate, err := tracker.ParseDate(c.store, *to)
+		if err != nil {
+			return err
+		}
+		if stats, err = tracker.GetStats(c.store, fromDate, toDate); err != nil {
+			return err
+		}
+	case *from != "" || *to != "":
+		return fmt.Errorf("--from and --to must be given together")
+	default:
+		day, err := tracker.ParseDate(c.store, *date)
+		if err != nil {
+			return err
+		}
+		if stats, err = tracker.GetPeriodStats(c.store, *period, day); err != nil {
+			return err
+		}
+	}
+
+	return c.print(stats, func(w io.Writer) {
+		fmt.Fprintf(w, "%s to %s\n\n", stats.From, stats.To)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Days\t%d\n", stats.Days)
+		fmt.Fprintf(tw, "Drinking days\t%d\n", stats.DrinkingDays)
+		fmt.Fprintf(tw, "Alcohol-free days\t%d\n", stats.AlcoholFreeDays)
+		fmt.Fprintf(tw, "Entries\t%d\n", stats.Entries)
+		fmt.Fprintf(tw, "Standard drinks\t%.2f\n", stats.StandardDrinks)
+		fmt.Fprintf(tw, "Alcohol\t%.1f g\n", stats.AlcoholGrams)
+		fmt.Fprintf(tw, "Drinks per day\t%.2f\n", stats.DrinksPerDay)
+		fmt.Fprintf(tw, "Drinks per drinking day\t%.2f\n", stats.DrinksPerDrinkingDay)
+		fmt.Fprintf(tw, "Spend\t%s\n", formatMoney(stats.Spend, stats.Currency))
+		tw.Flush()
+
+		if len(stats.MissingRates) > 0 {
+			fmt.Fprintf(w, "\nSpend leaves out %s: no exchange rate to %s\n", strings.Join(stats.MissingRates, ", "), stats.Currency)
+		}
+
+		if len(stats.Categories) > 0 {
+			fmt.Fprintln(w)
+			tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tw, "DRINK\tENTRIES\tVOLUME\tDRINKS\tSPEND\n")
+			for _, category := range stats.Categories {
+				fmt.Fprintf(tw, "%s\t%d\t%g mL\t%.2f\t%s\n", category.Category, category.Entries,
+					category.QuantityML, category.StandardDrinks, formatMoney(category.Spend, stats.Currency))
+			}
+			tw.Flush()
+		}
+	})
+}
+
+// exportResult is what export prints once it has written a file
+type exportResult struct {
+	File    string `json:"file"`
+	Entries int    `json:"entries"`
+}
+
+// runExport writes the drink history as CSV to a file, or to stdout for piping
+func runExport(c *cli, args []string) error {
+	fs := c.flagSet()
+	output := fs.String("o", "", "file to write (default stdout, with no summary)")
+
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err := tracker.ExportCSV(c.store, c.out)
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := tracker.ExportCSV(c.store, file)
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	result := exportResult{File: *output, Entries: rows}
+	return c.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Exported %d entries to %s\n", result.Entries, result.File)
+	})
+}
+
+// runImport adds the rows of a CSV file, or of stdin when the file is "-"
+func runImport(c *cli, args []string) error {
+	fs := c.flagSet()
+
+	positional, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	var input io.Reader = os.Stdin
+	if positional[0] != "-" {
+		file, err := os.Open(positional[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := tracker.ImportCSV(c.store, input)
+	if err != nil {
+		return err
+	}
+
+	err = c.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Imported %d entries, skipped %d duplicates\n", result.Imported, result.Duplicates)
+		for _, rowErr := range result.Errors {
+			fmt.Fprintf(w, "Row %d: %s\n", rowErr.Row, rowErr.Message)
+		}
+	})
+	if err == nil && len(result.Errors) > 0 {
+		err = fmt.Errorf("%d rows could not be imported", len(result.Errors))
+	}
+	return err
+}
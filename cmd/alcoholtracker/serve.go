@@ -0,0 +1,115 @@
+package main
+
+import (
+	"AlcoholTracker/server"
+	"AlcoholTracker/tracker"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// serveInfo is what serve prints once it is listening. The token is left out
+// unless asked for, so it doesn't end up in terminal scrollback or service logs.
+type serveInfo struct {
+	Addr  string `json:"addr"`
+	Token string `json:"token,omitempty"`
+}
+
+// tokenInfo is what token prints
+type tokenInfo struct {
+	Token string `json:"token"`
+	Reset bool   `json:"reset"`
+}
+
+// runServe serves the REST API on the database until interrupted
+func runServe(c *cli, args []string) error {
+	fs := c.flagSet()
+	addr := fs.String("addr", "127.0.0.1:8787", "address to listen on; use :8787 to accept other devices on the network")
+	printToken := fs.Bool("print-token", false, "print the API token once listening, as 'token' can't open the database while serve runs")
+
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	token, err := tracker.GetAPIToken(c.store)
+	if err != nil {
+		return err
+	}
+
+	// Reading goal statuses doesn't record finished periods, so do it once here
+	if err := tracker.RecordGoalHistory(c.store); err != nil {
+		return err
+	}
+
+	api, err := server.New(c.store, token)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(c.ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Listen before reporting, so a busy address fails here and port 0 prints the one picked
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- api.Serve(listener) }()
+
+	info := serveInfo{Addr: listener.Addr().String()}
+	if *printToken {
+		info.Token = token
+	}
+	err = c.print(info, func(w io.Writer) {
+		fmt.Fprintf(w, "Serving the REST API on http://%s (spec at /openapi.yaml)\n", info.Addr)
+		if info.Token != "" {
+			fmt.Fprintf(w, "Send \"Authorization: Bearer %s\" with every request. Press Ctrl+C to stop.\n", info.Token)
+			return
+		}
+		fmt.Fprintln(w, "Send \"Authorization: Bearer <token>\" with every request. Restart with --print-token to see the token. Press Ctrl+C to stop.")
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return api.Shutdown(shutdownCtx)
+}
+
+// runToken prints the REST API token, replacing it first with --reset
+func runToken(c *cli, args []string) error {
+	fs := c.flagSet()
+	reset := fs.Bool("reset", false, "replace the token, locking out existing clients")
+
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	getToken := tracker.GetAPIToken
+	if *reset {
+		getToken = tracker.ResetAPIToken
+	}
+	token, err := getToken(c.store)
+	if err != nil {
+		return err
+	}
+
+	info := tokenInfo{Token: token, Reset: *reset}
+	return c.print(info, func(w io.Writer) {
+		fmt.Fprintln(w, info.Token)
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"AlcoholTracker/tracker"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	db := filepath.Join(t.TempDir(), tracker.DefaultDBFile)
+	var added tracker.Record
+	runJSON(t, db, &added, "add", "Beer", "500", "--date", "2024-03-05")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stdout, printed := io.Pipe()
+	var stderr bytes.Buffer
+	exited := make(chan int, 1)
+	go func() {
+		exited <- run(ctx, []string{"serve", "-db", db, "--addr", "127.0.0.1:0", "--print-token", "-json"}, printed, &stderr)
+		printed.Close()
+	}()
+
+	var info serveInfo
+	if err := json.NewDecoder(stdout).Decode(&info); err != nil {
+		t.Fatalf("serve printed no address: %v (%s)", err, stderr.String())
+	}
+	if info.Token == "" {
+		t.Fatal("--print-token printed no token")
+	}
+
+	get := func(token string) int {
+		t.Helper()
+		r, err := http.NewRequest("GET", "http://"+info.Addr+"/api/v1/entries/"+added.Entry.ID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		response, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+	if code := get(""); code != http.StatusUnauthorized {
+		t.Errorf("a request without the token returned %d, want 401", code)
+	}
+	if code := get(info.Token); code != http.StatusOK {
+		t.Errorf("a request with the printed token returned %d, want 200", code)
+	}
+
+	cancel()
+	select {
+	case code := <-exited:
+		if code != 0 {
+			t.Errorf("serve exited with %d: %s", code, stderr.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not stop when cancelled")
+	}
+
+	// The database is released, so the token command sees the same token
+	var token tokenInfo
+	runJSON(t, db, &token, "token")
+	if token.Token != info.Token {
+		t.Errorf("token printed %q, want the served %q", token.Token, info.Token)
+	}
+}
@@ -3,28 +3,132 @@
 import {tracker} from '../models';
 import {context} from '../models';
 
-export function AddTrackerEntry(arg1:number,arg2:number,arg3:number,arg4:string,arg5:number,arg6:number):Promise<void>;
+export function AddTrackerEntry(arg1:number,arg2:number,arg3:number,arg4:string,arg5:number,arg6:string,arg7:number,arg8:string,arg9:string):Promise<void>;
 
-export function AddTrackerEntryUpdate(arg1:number,arg2:number,arg3:number,arg4:string,arg5:number,arg6:number,arg7:number):Promise<void>;
+export function ArchiveDrink(arg1:string,arg2:boolean):Promise<boolean>;
 
-export function DeleteDrink(arg1:number,arg2:number,arg3:number,arg4:string,arg5:number):Promise<boolean>;
+export function BackupToFile():Promise<string>;
+
+export function DeleteDrink(arg1:number,arg2:number,arg3:number,arg4:string):Promise<boolean>;
+
+export function DeleteExchangeRate(arg1:string,arg2:string):Promise<boolean>;
+
+export function DeleteGoal(arg1:string):Promise<boolean>;
+
+export function ExportCSV():Promise<string>;
+
+export function GetAPIToken():Promise<string>;
 
 export function GetAlcoholCategories():Promise<Array<string>>;
 
+export function GetBAC():Promise<tracker.BACEstimate>;
+
+export function GetBodyProfile():Promise<tracker.BodyProfile>;
+
+export function GetBudgetStatus(arg1:number,arg2:number):Promise<tracker.BudgetStatus>;
+
+export function GetCurrencies():Promise<Array<tracker.Currency>>;
+
+export function GetCurrentDrinkingDay():Promise<tracker.Date>;
+
+export function GetDayRolloverHour():Promise<number>;
+
+export function GetDayTier(arg1:number,arg2:number,arg3:number):Promise<tracker.TierResult>;
+
 export function GetDaysSinceLastDrink():Promise<number>;
 
-export function GetDrinkCount(arg1:number,arg2:number,arg3:number):Promise<number>;
+export function GetDefaultCurrency():Promise<tracker.Currency>;
+
+export function GetDisplayUnit():Promise<tracker.VolumeUnit>;
 
-export function GetDrinkTagColor(arg1:number,arg2:number,arg3:number):Promise<number>;
+export function GetDrink(arg1:number,arg2:number,arg3:number,arg4:string):Promise<tracker.DayData>;
 
-export function GetDrinks(arg1:number,arg2:number,arg3:number):Promise<string>;
+export function GetDrinkCatalog():Promise<Array<tracker.Drink>>;
+
+export function GetDrinkCount(arg1:number,arg2:number,arg3:number):Promise<number>;
 
 export function GetEntriesByDate(arg1:number,arg2:number,arg3:number,arg4:string):Promise<Array<tracker.DayData>>;
 
+export function GetEntriesInRange(arg1:tracker.Date,arg2:tracker.Date):Promise<Array<tracker.Record>>;
+
 export function GetEntriesOnDate(arg1:number,arg2:number,arg3:number):Promise<{[key: string]: Array<tracker.DayData>}>;
 
+export function GetExchangeRates():Promise<Array<tracker.ExchangeRate>>;
+
+export function GetGoalStatus():Promise<Array<tracker.GoalStatus>>;
+
+export function GetGoals():Promise<Array<tracker.Goal>>;
+
+export function GetMonthlyBudget():Promise<tracker.Money>;
+
+export function GetPeriodStats(arg1:string,arg2:number,arg3:number,arg4:number):Promise<tracker.PeriodStats>;
+
+export function GetReportingCurrency():Promise<tracker.Currency>;
+
+export function GetSnapshotPolicy():Promise<tracker.SnapshotPolicy>;
+
+export function GetSpendingInRange(arg1:tracker.Date,arg2:tracker.Date):Promise<tracker.SpendingReport>;
+
+export function GetSpendingReport(arg1:string,arg2:number,arg3:number,arg4:number):Promise<tracker.SpendingReport>;
+
+export function GetStandardDrink():Promise<tracker.StandardDrink>;
+
+export function GetStandardDrinkOptions():Promise<Array<tracker.StandardDrink>>;
+
+export function GetStatsInRange(arg1:tracker.Date,arg2:tracker.Date):Promise<tracker.PeriodStats>;
+
+export function GetStreaks():Promise<tracker.StreakSummary>;
+
+export function GetTiers():Promise<Array<tracker.Tier>>;
+
+export function GetTimezone():Promise<string>;
+
+export function GetVolumeUnits():Promise<Array<tracker.VolumeUnit>>;
+
+export function GetWeekEntries(arg1:number,arg2:number,arg3:number):Promise<Array<tracker.Record>>;
+
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportCSV():Promise<tracker.ImportResult>;
+
+export function ListSnapshots():Promise<Array<tracker.Snapshot>>;
+
+export function ResetTiers():Promise<boolean>;
+
+export function RestoreFromFile(arg1:string):Promise<tracker.RestoreResult>;
+
+export function RestoreSnapshot(arg1:string):Promise<boolean>;
+
+export function SaveBodyProfile(arg1:tracker.BodyProfile):Promise<boolean>;
+
+export function SaveDrink(arg1:string,arg2:number,arg3:number,arg4:string):Promise<boolean>;
+
+export function SaveGoal(arg1:tracker.Goal):Promise<tracker.Goal>;
+
+export function SaveSnapshotPolicy(arg1:tracker.SnapshotPolicy):Promise<boolean>;
+
+export function SaveTiers(arg1:Array<tracker.Tier>):Promise<boolean>;
+
+export function SetDayRolloverHour(arg1:number):Promise<boolean>;
+
+export function SetDefaultCurrency(arg1:string):Promise<boolean>;
+
+export function SetDisplayUnit(arg1:string):Promise<boolean>;
+
+export function SetExchangeRate(arg1:string,arg2:string,arg3:number):Promise<boolean>;
+
+export function SetMonthlyBudget(arg1:number,arg2:string):Promise<boolean>;
+
+export function SetReportingCurrency(arg1:string):Promise<boolean>;
+
+export function SetStandardDrink(arg1:string,arg2:number):Promise<boolean>;
+
+export function SetTimezone(arg1:string):Promise<boolean>;
+
 export function Shutdown(arg1:context.Context):Promise<void>;
 
+export function TakeSnapshot():Promise<tracker.Snapshot>;
+
+export function UpdateDrink(arg1:number,arg2:number,arg3:number,arg4:string,arg5:number,arg6:number,arg7:number,arg8:string,arg9:number,arg10:string,arg11:number,arg12:string,arg13:string):Promise<boolean>;
+
 export function ValidateFormDate(arg1:number,arg2:number,arg3:number):Promise<boolean>;
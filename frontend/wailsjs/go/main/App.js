@@ -2,54 +2,262 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function AddTrackerEntry(arg1, arg2, arg3, arg4, arg5, arg6) {
-  return window['go']['main']['App']['AddTrackerEntry'](arg1, arg2, arg3, arg4, arg5, arg6);
+export function AddTrackerEntry(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['main']['App']['AddTrackerEntry'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
 }
 
-export function AddTrackerEntryUpdate(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
-  return window['go']['main']['App']['AddTrackerEntryUpdate'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+export function ArchiveDrink(arg1, arg2) {
+  return window['go']['main']['App']['ArchiveDrink'](arg1, arg2);
 }
 
-export function DeleteDrink(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['main']['App']['DeleteDrink'](arg1, arg2, arg3, arg4, arg5);
+export function BackupToFile() {
+  return window['go']['main']['App']['BackupToFile']();
+}
+
+export function DeleteDrink(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['DeleteDrink'](arg1, arg2, arg3, arg4);
+}
+
+export function DeleteExchangeRate(arg1, arg2) {
+  return window['go']['main']['App']['DeleteExchangeRate'](arg1, arg2);
+}
+
+export function DeleteGoal(arg1) {
+  return window['go']['main']['App']['DeleteGoal'](arg1);
+}
+
+export function ExportCSV() {
+  return window['go']['main']['App']['ExportCSV']();
+}
+
+export function GetAPIToken() {
+  return window['go']['main']['App']['GetAPIToken']();
 }
 
 export function GetAlcoholCategories() {
   return window['go']['main']['App']['GetAlcoholCategories']();
 }
 
+export function GetBAC() {
+  return window['go']['main']['App']['GetBAC']();
+}
+
+export function GetBodyProfile() {
+  return window['go']['main']['App']['GetBodyProfile']();
+}
+
+export function GetBudgetStatus(arg1, arg2) {
+  return window['go']['main']['App']['GetBudgetStatus'](arg1, arg2);
+}
+
+export function GetCurrencies() {
+  return window['go']['main']['App']['GetCurrencies']();
+}
+
+export function GetCurrentDrinkingDay() {
+  return window['go']['main']['App']['GetCurrentDrinkingDay']();
+}
+
+export function GetDayRolloverHour() {
+  return window['go']['main']['App']['GetDayRolloverHour']();
+}
+
+export function GetDayTier(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetDayTier'](arg1, arg2, arg3);
+}
+
 export function GetDaysSinceLastDrink() {
   return window['go']['main']['App']['GetDaysSinceLastDrink']();
 }
 
-export function GetDrinkCount(arg1, arg2, arg3) {
-  return window['go']['main']['App']['GetDrinkCount'](arg1, arg2, arg3);
+export function GetDefaultCurrency() {
+  return window['go']['main']['App']['GetDefaultCurrency']();
+}
+
+export function GetDisplayUnit() {
+  return window['go']['main']['App']['GetDisplayUnit']();
 }
 
-export function GetDrinkTagColor(arg1, arg2, arg3) {
-  return window['go']['main']['App']['GetDrinkTagColor'](arg1, arg2, arg3);
+export function GetDrink(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetDrink'](arg1, arg2, arg3, arg4);
 }
 
-export function GetDrinks(arg1, arg2, arg3) {
-  return window['go']['main']['App']['GetDrinks'](arg1, arg2, arg3);
+export function GetDrinkCatalog() {
+  return window['go']['main']['App']['GetDrinkCatalog']();
+}
+
+export function GetDrinkCount(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetDrinkCount'](arg1, arg2, arg3);
 }
 
 export function GetEntriesByDate(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['GetEntriesByDate'](arg1, arg2, arg3, arg4);
 }
 
+export function GetEntriesInRange(arg1, arg2) {
+  return window['go']['main']['App']['GetEntriesInRange'](arg1, arg2);
+}
+
 export function GetEntriesOnDate(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetEntriesOnDate'](arg1, arg2, arg3);
 }
 
+export function GetExchangeRates() {
+  return window['go']['main']['App']['GetExchangeRates']();
+}
+
+export function GetGoalStatus() {
+  return window['go']['main']['App']['GetGoalStatus']();
+}
+
+export function GetGoals() {
+  return window['go']['main']['App']['GetGoals']();
+}
+
+export function GetMonthlyBudget() {
+  return window['go']['main']['App']['GetMonthlyBudget']();
+}
+
+export function GetPeriodStats(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetPeriodStats'](arg1, arg2, arg3, arg4);
+}
+
+export function GetReportingCurrency() {
+  return window['go']['main']['App']['GetReportingCurrency']();
+}
+
+export function GetSnapshotPolicy() {
+  return window['go']['main']['App']['GetSnapshotPolicy']();
+}
+
+export function GetSpendingInRange(arg1, arg2) {
+  return window['go']['main']['App']['GetSpendingInRange'](arg1, arg2);
+}
+
+export function GetSpendingReport(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetSpendingReport'](arg1, arg2, arg3, arg4);
+}
+
+export function GetStandardDrink() {
+  return window['go']['main']['App']['GetStandardDrink']();
+}
+
+export function GetStandardDrinkOptions() {
+  return window['go']['main']['App']['GetStandardDrinkOptions']();
+}
+
+export function GetStatsInRange(arg1, arg2) {
+  return window['go']['main']['App']['GetStatsInRange'](arg1, arg2);
+}
+
+export function GetStreaks() {
+  return window['go']['main']['App']['GetStreaks']();
+}
+
+export function GetTiers() {
+  return window['go']['main']['App']['GetTiers']();
+}
+
+export function GetTimezone() {
+  return window['go']['main']['App']['GetTimezone']();
+}
+
+export function GetVolumeUnits() {
+  return window['go']['main']['App']['GetVolumeUnits']();
+}
+
+export function GetWeekEntries(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetWeekEntries'](arg1, arg2, arg3);
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportCSV() {
+  return window['go']['main']['App']['ImportCSV']();
+}
+
+export function ListSnapshots() {
+  return window['go']['main']['App']['ListSnapshots']();
+}
+
+export function ResetTiers() {
+  return window['go']['main']['App']['ResetTiers']();
+}
+
+export function RestoreFromFile(arg1) {
+  return window['go']['main']['App']['RestoreFromFile'](arg1);
+}
+
+export function RestoreSnapshot(arg1) {
+  return window['go']['main']['App']['RestoreSnapshot'](arg1);
+}
+
+export function SaveBodyProfile(arg1) {
+  return window['go']['main']['App']['SaveBodyProfile'](arg1);
+}
+
+export function SaveDrink(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SaveDrink'](arg1, arg2, arg3, arg4);
+}
+
+export function SaveGoal(arg1) {
+  return window['go']['main']['App']['SaveGoal'](arg1);
+}
+
+export function SaveSnapshotPolicy(arg1) {
+  return window['go']['main']['App']['SaveSnapshotPolicy'](arg1);
+}
+
+export function SaveTiers(arg1) {
+  return window['go']['main']['App']['SaveTiers'](arg1);
+}
+
+export function SetDayRolloverHour(arg1) {
+  return window['go']['main']['App']['SetDayRolloverHour'](arg1);
+}
+
+export function SetDefaultCurrency(arg1) {
+  return window['go']['main']['App']['SetDefaultCurrency'](arg1);
+}
+
+export function SetDisplayUnit(arg1) {
+  return window['go']['main']['App']['SetDisplayUnit'](arg1);
+}
+
+export function SetExchangeRate(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetExchangeRate'](arg1, arg2, arg3);
+}
+
+export function SetMonthlyBudget(arg1, arg2) {
+  return window['go']['main']['App']['SetMonthlyBudget'](arg1, arg2);
+}
+
+export function SetReportingCurrency(arg1) {
+  return window['go']['main']['App']['SetReportingCurrency'](arg1);
+}
+
+export function SetStandardDrink(arg1, arg2) {
+  return window['go']['main']['App']['SetStandardDrink'](arg1, arg2);
+}
+
+export function SetTimezone(arg1) {
+  return window['go']['main']['App']['SetTimezone'](arg1);
+}
+
 export function Shutdown(arg1) {
   return window['go']['main']['App']['Shutdown'](arg1);
 }
 
+export function TakeSnapshot() {
+  return window['go']['main']['App']['TakeSnapshot']();
+}
+
+export function UpdateDrink(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12, arg13) {
+  return window['go']['main']['App']['UpdateDrink'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12, arg13);
+}
+
 export function ValidateFormDate(arg1, arg2, arg3) {
   return window['go']['main']['App']['ValidateFormDate'](arg1, arg2, arg3);
 }
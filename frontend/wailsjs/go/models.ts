@@ -1,10 +1,192 @@
 export namespace tracker {
 	
+	export class BACEstimate {
+	    current: number;
+	    peak: number;
+	    peak_at: number;
+	    sober_at: number;
+	    minutes_until_sober: number;
+	    curve: Array<BACPoint>;
+	
+	    static createFrom(source: any = {}) {
+	        return new BACEstimate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current = source["current"];
+	        this.peak = source["peak"];
+	        this.peak_at = source["peak_at"];
+	        this.sober_at = source["sober_at"];
+	        this.minutes_until_sober = source["minutes_until_sober"];
+	        this.curve = this.convertValues(source["curve"], BACPoint);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BACPoint {
+	    time: number;
+	    bac: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BACPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.bac = source["bac"];
+	    }
+	}
+	export class BodyProfile {
+	    weight_kg: number;
+	    sex: string;
+	    body_water_factor: number;
+	    elimination_rate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BodyProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.weight_kg = source["weight_kg"];
+	        this.sex = source["sex"];
+	        this.body_water_factor = source["body_water_factor"];
+	        this.elimination_rate = source["elimination_rate"];
+	    }
+	}
+	export class BudgetStatus {
+	    year: number;
+	    month: number;
+	    currency: string;
+	    missing_rates: Array<string>;
+	    budget: number;
+	    spent: number;
+	    remaining: number;
+	    over: boolean;
+	    days_left: number;
+	    daily_allowance: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BudgetStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.month = source["month"];
+	        this.currency = source["currency"];
+	        this.missing_rates = source["missing_rates"];
+	        this.budget = source["budget"];
+	        this.spent = source["spent"];
+	        this.remaining = source["remaining"];
+	        this.over = source["over"];
+	        this.days_left = source["days_left"];
+	        this.daily_allowance = source["daily_allowance"];
+	    }
+	}
+	export class CategorySpend {
+	    category: string;
+	    spend: number;
+	    standard_drinks: number;
+	    cost_per_drink: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategorySpend(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category = source["category"];
+	        this.spend = source["spend"];
+	        this.standard_drinks = source["standard_drinks"];
+	        this.cost_per_drink = source["cost_per_drink"];
+	    }
+	}
+	export class CategoryStats {
+	    category: string;
+	    entries: number;
+	    quantity_ml: number;
+	    alcohol_grams: number;
+	    standard_drinks: number;
+	    spend: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category = source["category"];
+	        this.entries = source["entries"];
+	        this.quantity_ml = source["quantity_ml"];
+	        this.alcohol_grams = source["alcohol_grams"];
+	        this.standard_drinks = source["standard_drinks"];
+	        this.spend = source["spend"];
+	    }
+	}
+	export class Currency {
+	    code: string;
+	    symbol: string;
+	    decimals: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Currency(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.symbol = source["symbol"];
+	        this.decimals = source["decimals"];
+	    }
+	}
+	export class Date {
+	    year: number;
+	    month: number;
+	    day: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Date(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.month = source["month"];
+	        this.day = source["day"];
+	    }
+	}
 	export class DayData {
+	    id: string;
 	    alcohol: string;
 	    quantity: number;
-	    cost: number;
-	    timestamp: number;
+	    cost_minor: number;
+	    currency: string;
+	    consumed_at: any;
+	    created_at: any;
+	    updated_at: any;
+	    abv: number;
+	    alcohol_grams: number;
+	    timestamp?: number;
+	    cost?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new DayData(source);
@@ -12,10 +194,690 @@ export namespace tracker {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
 	        this.alcohol = source["alcohol"];
 	        this.quantity = source["quantity"];
-	        this.cost = source["cost"];
+	        this.cost_minor = source["cost_minor"];
+	        this.currency = source["currency"];
+	        this.consumed_at = this.convertValues(source["consumed_at"], null);
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.abv = source["abv"];
+	        this.alcohol_grams = source["alcohol_grams"];
 	        this.timestamp = source["timestamp"];
+	        this.cost = source["cost"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DaySpend {
+	    date: Date;
+	    spend: number;
+	    standard_drinks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DaySpend(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = this.convertValues(source["date"], Date);
+	        this.spend = source["spend"];
+	        this.standard_drinks = source["standard_drinks"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Drink {
+	    name: string;
+	    abv: number;
+	    serving_ml: number;
+	    color: string;
+	    archived: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Drink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.abv = source["abv"];
+	        this.serving_ml = source["serving_ml"];
+	        this.color = source["color"];
+	        this.archived = source["archived"];
+	    }
+	}
+	export class ExchangeRate {
+	    from: string;
+	    to: string;
+	    rate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExchangeRate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.rate = source["rate"];
+	    }
+	}
+	export class Goal {
+	    id: string;
+	    kind: string;
+	    period: string;
+	    target: number;
+	    currency?: string;
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Goal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.period = source["period"];
+	        this.target = source["target"];
+	        this.currency = source["currency"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GoalProgress {
+	    from: Date;
+	    to: Date;
+	    actual: number;
+	    target: number;
+	    currency?: string;
+	    met: boolean;
+	    failed: boolean;
+	    days_left: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GoalProgress(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = this.convertValues(source["from"], Date);
+	        this.to = this.convertValues(source["to"], Date);
+	        this.actual = source["actual"];
+	        this.target = source["target"];
+	        this.currency = source["currency"];
+	        this.met = source["met"];
+	        this.failed = source["failed"];
+	        this.days_left = source["days_left"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GoalResult {
+	    goal_id: string;
+	    from: Date;
+	    to: Date;
+	    actual: number;
+	    target: number;
+	    currency?: string;
+	    passed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GoalResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.goal_id = source["goal_id"];
+	        this.from = this.convertValues(source["from"], Date);
+	        this.to = this.convertValues(source["to"], Date);
+	        this.actual = source["actual"];
+	        this.target = source["target"];
+	        this.currency = source["currency"];
+	        this.passed = source["passed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GoalStatus {
+	    goal: Goal;
+	    current: GoalProgress;
+	    history: Array<GoalResult>;
+	
+	    static createFrom(source: any = {}) {
+	        return new GoalStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.goal = this.convertValues(source["goal"], Goal);
+	        this.current = this.convertValues(source["current"], GoalProgress);
+	        this.history = this.convertValues(source["history"], GoalResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportResult {
+	    imported: number;
+	    duplicates: number;
+	    errors: Array<RowError>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.imported = source["imported"];
+	        this.duplicates = source["duplicates"];
+	        this.errors = this.convertValues(source["errors"], RowError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Money {
+	    amount: number;
+	    currency: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Money(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.amount = source["amount"];
+	        this.currency = source["currency"];
+	    }
+	}
+	export class PeriodStats {
+	    period: string;
+	    from: Date;
+	    to: Date;
+	    days: number;
+	    drinking_days: number;
+	    alcohol_free_days: number;
+	    entries: number;
+	    alcohol_grams: number;
+	    standard_drinks: number;
+	    spend: number;
+	    currency: string;
+	    missing_rates: Array<string>;
+	    drinks_per_day: number;
+	    drinks_per_drinking_day: number;
+	    categories: Array<CategoryStats>;
+	    drinks_by_hour: Array<number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new PeriodStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.from = this.convertValues(source["from"], Date);
+	        this.to = this.convertValues(source["to"], Date);
+	        this.days = source["days"];
+	        this.drinking_days = source["drinking_days"];
+	        this.alcohol_free_days = source["alcohol_free_days"];
+	        this.entries = source["entries"];
+	        this.alcohol_grams = source["alcohol_grams"];
+	        this.standard_drinks = source["standard_drinks"];
+	        this.spend = source["spend"];
+	        this.currency = source["currency"];
+	        this.missing_rates = source["missing_rates"];
+	        this.drinks_per_day = source["drinks_per_day"];
+	        this.drinks_per_drinking_day = source["drinks_per_drinking_day"];
+	        this.categories = this.convertValues(source["categories"], CategoryStats);
+	        this.drinks_by_hour = source["drinks_by_hour"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Record {
+	    date: Date;
+	    category: string;
+	    entry: DayData;
+	
+	    static createFrom(source: any = {}) {
+	        return new Record(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = this.convertValues(source["date"], Date);
+	        this.category = source["category"];
+	        this.entry = this.convertValues(source["entry"], DayData);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RestoreResult {
+	    entries: number;
+	    skipped_entries: number;
+	    drinks: number;
+	    skipped_drinks: number;
+	    settings: number;
+	    skipped_settings: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = source["entries"];
+	        this.skipped_entries = source["skipped_entries"];
+	        this.drinks = source["drinks"];
+	        this.skipped_drinks = source["skipped_drinks"];
+	        this.settings = source["settings"];
+	        this.skipped_settings = source["skipped_settings"];
+	    }
+	}
+	export class RowError {
+	    row: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RowError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.message = source["message"];
+	    }
+	}
+	export class Snapshot {
+	    name: string;
+	    path: string;
+	    taken_at: any;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.taken_at = this.convertValues(source["taken_at"], null);
+	        this.size = source["size"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SnapshotPolicy {
+	    interval_hours: number;
+	    daily: number;
+	    weekly: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.interval_hours = source["interval_hours"];
+	        this.daily = source["daily"];
+	        this.weekly = source["weekly"];
+	    }
+	}
+	export class SpendingReport {
+	    period: string;
+	    from: Date;
+	    to: Date;
+	    currency: string;
+	    missing_rates: Array<string>;
+	    total: number;
+	    standard_drinks: number;
+	    cost_per_drink: number;
+	    per_day: number;
+	    categories: Array<CategorySpend>;
+	    top_days: Array<DaySpend>;
+	
+	    static createFrom(source: any = {}) {
+	        return new SpendingReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.from = this.convertValues(source["from"], Date);
+	        this.to = this.convertValues(source["to"], Date);
+	        this.currency = source["currency"];
+	        this.missing_rates = source["missing_rates"];
+	        this.total = source["total"];
+	        this.standard_drinks = source["standard_drinks"];
+	        this.cost_per_drink = source["cost_per_drink"];
+	        this.per_day = source["per_day"];
+	        this.categories = this.convertValues(source["categories"], CategorySpend);
+	        this.top_days = this.convertValues(source["top_days"], DaySpend);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StandardDrink {
+	    code: string;
+	    name: string;
+	    grams: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StandardDrink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.name = source["name"];
+	        this.grams = source["grams"];
+	    }
+	}
+	export class Streak {
+	    kind: string;
+	    start: Date;
+	    end: Date;
+	    days: number;
+	    current: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Streak(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.start = this.convertValues(source["start"], Date);
+	        this.end = this.convertValues(source["end"], Date);
+	        this.days = source["days"];
+	        this.current = source["current"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StreakSummary {
+	    current_sober: number;
+	    current_drinking: number;
+	    longest_sober: Streak;
+	    longest_drinking: Streak;
+	    history: Array<Streak>;
+	
+	    static createFrom(source: any = {}) {
+	        return new StreakSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current_sober = source["current_sober"];
+	        this.current_drinking = source["current_drinking"];
+	        this.longest_sober = this.convertValues(source["longest_sober"], Streak);
+	        this.longest_drinking = this.convertValues(source["longest_drinking"], Streak);
+	        this.history = this.convertValues(source["history"], Streak);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Tier {
+	    name: string;
+	    min_grams: number;
+	    color: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tier(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.min_grams = source["min_grams"];
+	        this.color = source["color"];
+	    }
+	}
+	export class TierResult {
+	    name: string;
+	    index: number;
+	    tier_count: number;
+	    color: string;
+	    drinks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TierResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.index = source["index"];
+	        this.tier_count = source["tier_count"];
+	        this.color = source["color"];
+	        this.drinks = source["drinks"];
+	    }
+	}
+	export class VolumeUnit {
+	    code: string;
+	    symbol: string;
+	    ml: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VolumeUnit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.symbol = source["symbol"];
+	        this.ml = source["ml"];
 	    }
 	}
 
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"AlcoholTracker/server"
+	"AlcoholTracker/tracker"
 	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,11 +19,68 @@ import (
 var assets embed.FS
 
 func main() {
+	dbFlag := flag.String("db", "", "path to the tracker database (overrides $"+tracker.DBPathEnv+" and the config file)")
+	moveLegacy := flag.Bool("move-legacy-db", false, "move a tracker.db found in the working directory to the data directory")
+	backupPath := flag.String("backup", "", "write a JSON backup of the database to this file and exit")
+	restorePath := flag.String("restore", "", "restore the JSON backup in this file and exit")
+	restoreMode := flag.String("restore-mode", tracker.RestoreMerge, "how -restore loads a backup: merge or replace")
+	serveAddr := flag.String("serve", "", "also serve the REST API on this address while the app runs, e.g. :8787")
+	printToken := flag.Bool("print-api-token", false, "print the REST API token to stdout when -serve starts")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "list the migrations the database needs and exit without changing it")
+	flag.Parse()
+
+	dbPath, err := tracker.ResolveDBPath(*dbFlag)
+	if err != nil {
+		log.Fatal("Failed to resolve database path:", err)
+	}
+
+	// Checked before the legacy move so nothing on disk changes
+	if *migrateDryRun {
+		if err := printPendingMigrations(dbPath); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *moveLegacy {
+		moved, err := tracker.MoveLegacyDB(dbPath)
+		if moved != "" {
+			log.Printf("Moved %s to %s", moved, dbPath)
+		}
+		if err != nil {
+			log.Println("Failed to move legacy database:", err)
+		}
+	}
+
+	store, err := tracker.InitDB(dbPath)
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+
+	// Backups and restores run headless
+	if *backupPath != "" || *restorePath != "" {
+		err := runBackupCommand(store, *backupPath, *restorePath, *restoreMode)
+		store.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// The API shares the open store, as only one process can hold the file
+	var api *server.Server
+	if *serveAddr != "" {
+		api, err = startAPI(store, *serveAddr, *printToken)
+		if err != nil {
+			log.Println("Failed to start REST API:", err)
+		}
+	}
+
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(store, tracker.NewSnapshotter(store), api)
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:         "AlcoholTracker",
 		Width:         1024,
 		Height:        550,
@@ -27,6 +91,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 67, G: 118, B: 108, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.Shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -37,3 +102,87 @@ func main() {
 	}
 
 }
+
+// runBackupCommand writes a backup to backupPath and/or restores the one in restorePath
+func runBackupCommand(store tracker.Store, backupPath, restorePath, restoreMode string) error {
+	if backupPath != "" {
+		file, err := os.Create(backupPath)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %v", err)
+		}
+		defer file.Close()
+
+		backup, err := tracker.WriteBackup(store, file)
+		if err != nil {
+			return fmt.Errorf("failed to write backup: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write backup: %v", err)
+		}
+		fmt.Printf("Backed up %d entries to %s\n", len(backup.Entries), backupPath)
+	}
+
+	if restorePath != "" {
+		file, err := os.Open(restorePath)
+		if err != nil {
+			return fmt.Errorf("failed to open backup: %v", err)
+		}
+		defer file.Close()
+
+		result, err := tracker.RestoreBackupFile(store, file, restoreMode)
+		if err != nil {
+			return fmt.Errorf("failed to restore backup: %v", err)
+		}
+		fmt.Printf("Restored %d entries (%d already present), %d drinks and %d settings from %s\n",
+			result.Entries, result.SkippedEntries, result.Drinks, result.Settings, restorePath)
+	}
+	return nil
+}
+
+// printPendingMigrations lists the migrations InitDB would apply to the database at path
+func printPendingMigrations(path string) error {
+	pending, err := tracker.DryRunMigrations(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("%s does not exist yet and will be created at schema version %d\n", path, tracker.CurrentSchemaVersion())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %v", err)
+	}
+
+	if len(pending) == 0 {
+		fmt.Printf("%s is up to date (schema version %d)\n", path, tracker.CurrentSchemaVersion())
+		return nil
+	}
+
+	fmt.Printf("%s needs %d migrations to reach schema version %d:\n", path, len(pending), tracker.CurrentSchemaVersion())
+	for _, m := range pending {
+		fmt.Printf("  %d: %s\n", m.Version, m.Description)
+	}
+	return nil
+}
+
+// startAPI serves the REST API on addr in the background. The token is only
+// printed when asked for, and never logged.
+func startAPI(store tracker.Store, addr string, printToken bool) (*server.Server, error) {
+	token, err := tracker.GetAPIToken(store)
+	if err != nil {
+		return nil, err
+	}
+	if printToken {
+		fmt.Println("REST API token:", token)
+	}
+
+	api, err := server.New(store, token)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := api.ListenAndServe(addr); err != nil {
+			log.Println("REST API stopped:", err)
+		}
+	}()
+	log.Println("REST API serving on", addr)
+	return api, nil
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"AlcoholTracker/tracker"
+	"fmt"
+	"net/http"
+)
+
+// entryInput is the body of POST /api/v1/entries
+type entryInput struct {
+	Date     string  `json:"date"` // YYYY-MM-DD, or "" for the date now
+	Time     string  `json:"time"` // HH:MM, or "" for now (noon on past dates)
+	Drink    string  `json:"drink"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"` // Unit of Quantity, or "" for mL
+	Cost     float64 `json:"cost"` // In major units (e.g. dollars) of Currency
+	Currency string  `json:"currency"`
+}
+
+// entryPatch is the body of PATCH /api/v1/entries/{id}; fields left out are kept
+type entryPatch struct {
+	Date     *string  `json:"date"`
+	Time     *string  `json:"time"`
+	Drink    *string  `json:"drink"`
+	Quantity *float64 `json:"quantity"`
+	Unit     string   `json:"unit"`
+	Cost     *float64 `json:"cost"`
+	Currency *string  `json:"currency"` // Without Cost, the amount is kept in the new currency
+}
+
+// handleListEntries returns the entries between ?from= and ?to=, by default the week up to today
+func (s *Server) handleListEntries(w http.ResponseWriter, r *http.Request) {
+	to, err := tracker.ParseDate(s.store, r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	from := tracker.DateOf(to.Time().AddDate(0, 0, -6))
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = tracker.ParseDate(s.store, value); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if to.Before(from) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("range end %s is before its start %s", to, from))
+		return
+	}
+
+	records, err := tracker.GetEntriesInRange(s.store, from.Time(), to.Time())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, records)
+}
+
+func (s *Server) handleAddEntry(w http.ResponseWriter, r *http.Request) {
+	var input entryInput
+	if err := readJSON(w, r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	record, err := s.addEntry(input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, record)
+}
+
+// addEntry validates and stores a new entry, returning it as stored
+func (s *Server) addEntry(input entryInput) (tracker.Record, error) {
+	// Without a date, LogEntry stores the drink under the date now
+	var date tracker.Date
+	if input.Date != "" {
+		var err error
+		if date, err = tracker.ParseDate(s.store, input.Date); err != nil {
+			return tracker.Record{}, err
+		}
+	}
+
+	return tracker.LogEntry(s.store, tracker.EntryInput{
+		Date:     date,
+		Time:     input.Time,
+		Drink:    input.Drink,
+		Quantity: input.Quantity,
+		Unit:     input.Unit,
+		Cost:     input.Cost,
+		Currency: input.Currency,
+	}, s.now())
+}
+
+func (s *Server) handleGetEntry(w http.ResponseWriter, r *http.Request) {
+	record, err := tracker.FindRecord(s.store, r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) handleUpdateEntry(w http.ResponseWriter, r *http.Request) {
+	var patch entryPatch
+	if err := readJSON(w, r, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	record, err := tracker.FindRecord(s.store, r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	record, err = s.updateEntry(record, patch)
+	if err != nil {
+		writeStoreError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+// updateEntry applies a patch to a stored entry, returning it as stored
+func (s *Server) updateEntry(record tracker.Record, patch entryPatch) (tracker.Record, error) {
+	edit := tracker.EntryPatch{
+		Time:     patch.Time,
+		Drink:    patch.Drink,
+		Quantity: patch.Quantity,
+		Unit:     patch.Unit,
+		Cost:     patch.Cost,
+		Currency: patch.Currency,
+	}
+	if patch.Date != nil {
+		date, err := tracker.ParseDate(s.store, *patch.Date)
+		if err != nil {
+			return record, err
+		}
+		edit.Date = &date
+	}
+
+	return tracker.EditEntry(s.store, record.Date, record.Entry.ID, edit)
+}
+
+func (s *Server) handleDeleteEntry(w http.ResponseWriter, r *http.Request) {
+	record, err := tracker.FindRecord(s.store, r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = s.store.DeleteEntry(record.Date.Year, record.Date.Month, record.Date.Day, record.Entry.ID)
+	if err != nil {
+		writeStoreError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"AlcoholTracker/tracker"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testToken = "secret"
+
+// newTestServer serves a memory store in UTC with a 05:00 rollover, at a fixed time
+func newTestServer(t *testing.T, now time.Time) *Server {
+	t.Helper()
+	store := tracker.NewMemoryStore()
+	if err := tracker.SetTimezone(store, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.SetDayRolloverHour(store, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(store, testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return now }
+	return s
+}
+
+// do sends an authorized request and decodes a JSON response into v
+func do(t *testing.T, s *Server, method, path, body string, v interface{}) int {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if v != nil && w.Code < 300 {
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: invalid response: %v", method, path, err)
+		}
+	}
+	return w.Code
+}
+
+func TestAddEntryBeforeRollover(t *testing.T) {
+	now := time.Date(2024, 3, 6, 1, 30, 0, 0, time.UTC)
+	s := newTestServer(t, now)
+
+	var record tracker.Record
+	if code := do(t, s, "POST", "/api/v1/entries", `{"drink": "Beer", "quantity": 500}`, &record); code != http.StatusCreated {
+		t.Fatalf("POST returned %d, want 201", code)
+	}
+
+	// 01:30 belongs to the drinking day of the 5th, but was had on the 6th
+	if want := (tracker.Date{Year: 2024, Month: 3, Day: 6}); record.Date != want {
+		t.Errorf("stored on %s, want %s", record.Date, want)
+	}
+	if !record.Entry.ConsumedAt.Equal(now) {
+		t.Errorf("consumed at %v, want %v", record.Entry.ConsumedAt, now)
+	}
+
+	// The same goes for a date given as the current drinking day
+	if code := do(t, s, "POST", "/api/v1/entries", `{"date": "2024-03-05", "drink": "Beer", "quantity": 500}`, &record); code != http.StatusCreated {
+		t.Fatalf("POST returned %d, want 201", code)
+	}
+	if record.Date.Day != 6 || !record.Entry.ConsumedAt.Equal(now) {
+		t.Errorf("stored on %s at %v, want the 6th at %v", record.Date, record.Entry.ConsumedAt, now)
+	}
+
+	// Other dates without a time get noon
+	if code := do(t, s, "POST", "/api/v1/entries", `{"date": "2024-03-01", "drink": "Wine", "quantity": 150}`, &record); code != http.StatusCreated {
+		t.Fatalf("POST returned %d, want 201", code)
+	}
+	if noon := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC); record.Date.Day != 1 || !record.Entry.ConsumedAt.Equal(noon) {
+		t.Errorf("stored on %s at %v, want the 1st at noon", record.Date, record.Entry.ConsumedAt)
+	}
+}
+
+func TestAddEntryRejectsInvalidInput(t *testing.T) {
+	s := newTestServer(t, time.Now())
+
+	bodies := []string{
+		`{"drink": "Moonshine", "quantity": 50}`,
+		`{"drink": "", "quantity": 50}`,
+		`{"drink": "Beer", "quantity": 0}`,
+		`{"drink": "Beer", "quantity": 500, "unit": "barrel"}`,
+		`{"drink": "Beer", "quantity": 500, "cost": -1}`,
+		`{"drink": "Beer", "quantity": 500, "date": "2024-02-30"}`,
+		`{"drink": "Beer", "quantity": 500, "colour": "amber"}`,
+	}
+	for _, body := range bodies {
+		if code := do(t, s, "POST", "/api/v1/entries", body, nil); code != http.StatusBadRequest {
+			t.Errorf("POST %s returned %d, want 400", body, code)
+		}
+	}
+}
+
+func TestUpdateAndDeleteEntry(t *testing.T) {
+	s := newTestServer(t, time.Date(2024, 3, 6, 20, 0, 0, 0, time.UTC))
+
+	var record tracker.Record
+	if code := do(t, s, "POST", "/api/v1/entries", `{"drink": "Beer", "quantity": 500, "time": "19:15", "cost": 6.5}`, &record); code != http.StatusCreated {
+		t.Fatalf("POST returned %d, want 201", code)
+	}
+	path := "/api/v1/entries/" + record.Entry.ID
+
+	var updated tracker.Record
+	if code := do(t, s, "PATCH", path, `{"drink": "Wine", "date": "2024-03-04", "currency": "EUR"}`, &updated); code != http.StatusOK {
+		t.Fatalf("PATCH returned %d, want 200", code)
+	}
+	entry := updated.Entry
+	if updated.Date.Day != 4 || entry.Alcohol != "Wine" || entry.ABV != 12 || entry.Cost != 650 || entry.Currency != "EUR" ||
+		entry.ConsumedAt.Format("15:04") != "19:15" {
+		t.Errorf("PATCH stored %+v on %s, want the wine moved to the 4th at 19:15 costing 6.50 EUR", entry, updated.Date)
+	}
+
+	if code := do(t, s, "PATCH", path, `{"drink": "Moonshine"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("PATCH to an unknown drink returned %d, want 400", code)
+	}
+
+	if code := do(t, s, "DELETE", path, "", nil); code != http.StatusNoContent {
+		t.Errorf("DELETE returned %d, want 204", code)
+	}
+	if code := do(t, s, "GET", path, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET of a deleted entry returned %d, want 404", code)
+	}
+}
+
+func TestRequestsNeedToken(t *testing.T) {
+	s := newTestServer(t, time.Now())
+
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{"no header", ""},
+		{"wrong token", "Bearer wrong"},
+		{"token prefix", "Bearer " + testToken[:3]},
+		{"token without scheme", testToken},
+		{"other scheme", "Basic " + testToken},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/api/v1/entries", nil)
+		if test.authorization != "" {
+			r.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: returned %d, want 401", test.name, w.Code)
+		}
+		if w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: no WWW-Authenticate header", test.name)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.yaml", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("the spec returned %d without the token, want 200", w.Code)
+	}
+
+	if _, err := New(tracker.NewMemoryStore(), ""); err == nil {
+		t.Error("a server was created without a token")
+	}
+}
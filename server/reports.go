@@ -0,0 +1,166 @@
+package server
+
+import (
+	"AlcoholTracker/tracker"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// handleStats summarises ?period= (week, month or year) around ?date=, or the
+// custom range ?from= to ?to=
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+
+	if from != "" || to != "" {
+		if from == "" || to == "" {
+			writeError(w, http.StatusBadRequest, errors.New("from and to must be given together"))
+			return
+		}
+
+		fromDate, err := tracker.ParseDate(s.store, from)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		toDate, err := tracker.ParseDate(s.store, to)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if toDate.Before(fromDate) {
+			writeError(w, http.StatusBadRequest, errors.New("to must not be before from"))
+			return
+		}
+
+		stats, err := tracker.GetStats(s.store, fromDate, toDate)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, stats)
+		return
+	}
+
+	period := query.Get("period")
+	if period == "" {
+		period = tracker.PeriodWeek
+	}
+	if _, _, err := tracker.PeriodBounds(period, tracker.Date{}); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	date, err := tracker.ParseDate(s.store, query.Get("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := tracker.GetPeriodStats(s.store, period, date)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *Server) handleListDrinks(w http.ResponseWriter, r *http.Request) {
+	drinks, err := s.store.GetDrinks()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, drinks)
+}
+
+// handleSaveDrink adds the drink named in the path, or replaces it if it exists
+func (s *Server) handleSaveDrink(w http.ResponseWriter, r *http.Request) {
+	var drink tracker.Drink
+	if err := readJSON(w, r, &drink); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	name := strings.TrimSpace(r.PathValue("name"))
+	if drink.Name != "" && strings.TrimSpace(drink.Name) != name {
+		writeError(w, http.StatusBadRequest, errors.New("the drink name in the body must match the one in the path"))
+		return
+	}
+	drink.Name = name
+
+	if err := tracker.SaveDrink(s.store, drink); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, drink)
+}
+
+func (s *Server) handleListGoals(w http.ResponseWriter, r *http.Request) {
+	goals, err := tracker.GetGoals(s.store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, goals)
+}
+
+func (s *Server) handleGoalStatus(w http.ResponseWriter, r *http.Request) {
+	statuses, err := tracker.GetGoalStatuses(s.store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) handleAddGoal(w http.ResponseWriter, r *http.Request) {
+	var goal tracker.Goal
+	if err := readJSON(w, r, &goal); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if goal.ID != "" {
+		writeError(w, http.StatusBadRequest, errors.New("new goals get their ID from the server; use PUT to change a goal"))
+		return
+	}
+
+	goal, err := tracker.SaveGoal(s.store, goal)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, goal)
+}
+
+// handleUpdateGoal changes the target of the goal in the path; its kind and period are fixed
+func (s *Server) handleUpdateGoal(w http.ResponseWriter, r *http.Request) {
+	var goal tracker.Goal
+	if err := readJSON(w, r, &goal); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id := r.PathValue("id")
+	if goal.ID != "" && goal.ID != id {
+		writeError(w, http.StatusBadRequest, errors.New("the goal ID in the body must match the one in the path"))
+		return
+	}
+	goal.ID = id
+
+	goal, err := tracker.SaveGoal(s.store, goal)
+	if err != nil {
+		writeStoreError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, goal)
+}
+
+func (s *Server) handleDeleteGoal(w http.ResponseWriter, r *http.Request) {
+	if err := tracker.DeleteGoal(s.store, r.PathValue("id")); err != nil {
+		writeStoreError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
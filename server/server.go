@@ -0,0 +1,156 @@
+// Package server exposes the tracker as a JSON REST API, so scripts and phones
+// on the local network can log and query drinks. It is described by openapi.yaml.
+package server
+
+import (
+	"AlcoholTracker/tracker"
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// maxBodyBytes caps request bodies; the largest legitimate one is a few hundred bytes
+const maxBodyBytes = 64 << 10
+
+// Server serves the REST API for a store. Every request except the OpenAPI
+// spec must carry the token as "Authorization: Bearer <token>".
+type Server struct {
+	store tracker.Store
+	token string
+	mux   *http.ServeMux
+	http  *http.Server
+	now   func() time.Time // The clock new entries are logged by
+}
+
+// New creates a server for store that accepts the given token
+func New(store tracker.Store, token string) (*Server, error) {
+	if token == "" {
+		return nil, errors.New("the API needs a token")
+	}
+
+	s := &Server{store: store, token: token, mux: http.NewServeMux(), now: time.Now}
+	s.http = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	s.mux.HandleFunc("GET /openapi.yaml", s.handleSpec)
+
+	s.mux.HandleFunc("GET /api/v1/entries", s.handleListEntries)
+	s.mux.HandleFunc("POST /api/v1/entries", s.handleAddEntry)
+	s.mux.HandleFunc("GET /api/v1/entries/{id}", s.handleGetEntry)
+	s.mux.HandleFunc("PATCH /api/v1/entries/{id}", s.handleUpdateEntry)
+	s.mux.HandleFunc("DELETE /api/v1/entries/{id}", s.handleDeleteEntry)
+
+	s.mux.HandleFunc("GET /api/v1/stats", s.handleStats)
+
+	s.mux.HandleFunc("GET /api/v1/drinks", s.handleListDrinks)
+	s.mux.HandleFunc("PUT /api/v1/drinks/{name}", s.handleSaveDrink)
+
+	s.mux.HandleFunc("GET /api/v1/goals", s.handleListGoals)
+	s.mux.HandleFunc("POST /api/v1/goals", s.handleAddGoal)
+	s.mux.HandleFunc("GET /api/v1/goals/status", s.handleGoalStatus)
+	s.mux.HandleFunc("PUT /api/v1/goals/{id}", s.handleUpdateGoal)
+	s.mux.HandleFunc("DELETE /api/v1/goals/{id}", s.handleDeleteGoal)
+
+	return s, nil
+}
+
+// ServeHTTP checks the token and routes the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/openapi.yaml" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="AlcoholTracker"`)
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid API token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized compares the bearer token in constant time
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// ListenAndServe serves the API on addr until Shutdown is called
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves the API on listener until Shutdown is called
+func (s *Server) Serve(listener net.Listener) error {
+	log.Println("REST API listening on", listener.Addr())
+	err := s.http.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting requests and waits for those in flight, so the store
+// can be closed afterwards
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) handleSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+// apiError is the body of every error response
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Failed to write API response:", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+// writeStoreError answers with 404 for unknown IDs and status for anything else
+func writeStoreError(w http.ResponseWriter, status int, err error) {
+	var notFound tracker.NotFoundError
+	if errors.As(err, &notFound) {
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err)
+}
+
+// readJSON decodes a request body into v, rejecting unknown fields so typos are noticed
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid request body: expected a single JSON object")
+	}
+	return nil
+}
@@ -1,67 +1,171 @@
 package tracker
 
 import (
-	"time"
-
-	"github.com/dgraph-io/badger"
+	"errors"
+	"fmt"
+	"strings"
 )
 
-// Alcohol types with their Alcohol By Volume (ABV) percentages
-var alcoholMap = map[string]float64{
-	"Beer":    5.0,  // 5% ABV
-	"Soju":    16.0, // 16% ABV
-	"Wine":    12.0, // 12% ABV
-	"Vodka":   40.0, // 40% ABV
-	"Rum":     40.0, // 40% ABV
-	"Whiskey": 40.0, // 40% ABV
-	"Gin":     37.5, // 37.5% ABV
+// Drink is a catalog entry that entries refer to by Name
+type Drink struct {
+	Name      string  `json:"name"`
+	ABV       float64 `json:"abv"`        // Alcohol By Volume, in percent
+	ServingML float64 `json:"serving_ml"` // Default serving volume
+	Color     string  `json:"color"`
+	Archived  bool    `json:"archived"` // Hidden from pickers, kept for history
+}
+
+// Drinks seeded into an empty catalog
+var defaultDrinks = []Drink{
+	{Name: "Beer", ABV: 5.0, ServingML: 355, Color: "#f2b134"},
+	{Name: "Soju", ABV: 16.0, ServingML: 50, Color: "#60aa9b"},
+	{Name: "Wine", ABV: 12.0, ServingML: 150, Color: "#8e2043"},
+	{Name: "Vodka", ABV: 40.0, ServingML: 44, Color: "#a7c7e7"},
+	{Name: "Rum", ABV: 40.0, ServingML: 44, Color: "#a0522d"},
+	{Name: "Whiskey", ABV: 40.0, ServingML: 44, Color: "#c47f17"},
+	{Name: "Gin", ABV: 37.5, ServingML: 44, Color: "#43766c"},
 }
 
-// getAlcoholTypes returns a slice of all the keys in alcoholMap
-func GetAlcoholTypes() []string {
-	keys := make([]string, 0, len(alcoholMap))
-	for key := range alcoholMap {
-		keys = append(keys, key)
+// ABV the alcohol backfill migration assumed for drinks missing from the catalog
+const fallbackABV = 40.0
+
+// Catalog indexes drinks by name
+type Catalog map[string]Drink
+
+// LoadCatalog reads every drink, including archived ones, from the store
+func LoadCatalog(s Store) (Catalog, error) {
+	drinks, err := s.GetDrinks()
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := make(Catalog, len(drinks))
+	for _, drink := range drinks {
+		catalog[drink.Name] = drink
 	}
-	return keys
+	return catalog, nil
 }
 
-// Function to calculate standard drinks
-func CalculateStandardDrinks(volumeML float64, alcoholType string) float64 {
-	abv, exists := alcoholMap[alcoholType]
-	standardDrinks := 0.0
-	// Formula: (volume in mL * ABV%) / 17.7
+// ABV returns the catalog ABV for a drink, failing if it is not in the catalog
+func (c Catalog) ABV(name string) (float64, error) {
+	drink, exists := c[name]
 	if !exists {
-		standardDrinks = (volumeML * (40.0 / 100)) / 17.7
-	} else {
-		standardDrinks = (volumeML * (abv / 100)) / 17.7
+		return 0, fmt.Errorf("unknown drink '%s': add it to the catalog or give its ABV", name)
 	}
-	return standardDrinks
+	return drink.ABV, nil
 }
 
-// GetTotalDrinksOnDay calculates the total number of standard drinks consumed on a given day
-func GetTotalDrinksOnDay(year, month, day int) (float64, error) {
-	totalDrinks := 0.0
-	drinksFound := false
-
-	for category := range alcoholMap {
-		entries, err := GetEntriesByDateCategory(year, month, day, category)
-		if err != nil {
-			// If key doesn't exist, skip this category
-			if err == badger.ErrKeyNotFound {
-				continue
-			}
+// GetAlcoholTypes returns the names of all non-archived drinks, sorted
+func GetAlcoholTypes(s Store) ([]string, error) {
+	catalog, err := LoadCatalog(s)
+	if err != nil {
+		return []string{}, err
+	}
 
+	keys := []string{}
+	for _, name := range sortedKeys(catalog) {
+		if !catalog[name].Archived {
+			keys = append(keys, name)
 		}
+	}
+	return keys, nil
+}
 
-		if len(entries) > 0 {
-			drinksFound = true
-		}
+// SaveDrink validates a drink and adds it to the catalog, replacing any drink with the same name
+func SaveDrink(s Store, drink Drink) error {
+	drink.Name = strings.TrimSpace(drink.Name)
+	if drink.Name == "" {
+		return errors.New("drink name must not be empty")
+	}
+	if drink.ABV < 0 || drink.ABV > 100 {
+		return errors.New("ABV must be between 0 and 100")
+	}
+	if drink.ServingML <= 0 {
+		return errors.New("serving volume must be greater than 0")
+	}
+	return s.SaveDrink(drink)
+}
+
+// ArchiveDrink hides or restores a drink without touching entries that use it
+func ArchiveDrink(s Store, name string, archived bool) error {
+	catalog, err := LoadCatalog(s)
+	if err != nil {
+		return err
+	}
+
+	drink, exists := catalog[name]
+	if !exists {
+		return fmt.Errorf("drink '%s' not found in catalog", name)
+	}
+
+	drink.Archived = archived
+	return s.SaveDrink(drink)
+}
+
+// Density of ethanol in g/mL
+const ethanolDensity = 0.789
+
+// Function to calculate standard drinks under the given definition
+func CalculateStandardDrinks(volumeML float64, abv float64, def StandardDrink) float64 {
+	return StandardDrinksFromGrams(AlcoholGrams(volumeML, abv), def)
+}
+
+// AlcoholGrams returns the grams of pure alcohol in a volume at the given ABV
+func AlcoholGrams(volumeML float64, abv float64) float64 {
+	return volumeML * (abv / 100) * ethanolDensity
+}
+
+// StandardDrinksFromGrams converts grams of pure alcohol to standard drinks under the given definition
+func StandardDrinksFromGrams(grams float64, def StandardDrink) float64 {
+	return grams / def.Grams
+}
 
-		for _, entry := range entries {
-			// Assuming quantity represents volume in mL
-			totalDrinks += CalculateStandardDrinks(float64(entry.Quantity), category)
+// stampAlcohol records the drink's current catalog ABV on an entry that has none,
+// and recomputes the alcohol grams from the entry's quantity. An entry without
+// an ABV for a drink missing from the catalog is rejected.
+func stampAlcohol(s Store, data *DayData) error {
+	if data.ABV == 0 {
+		catalog, err := LoadCatalog(s)
+		if err != nil {
+			return err
 		}
+		if data.ABV, err = catalog.ABV(data.Alcohol); err != nil {
+			return err
+		}
+	}
+
+	data.AlcoholGrams = AlcoholGrams(data.Quantity, data.ABV)
+	return nil
+}
+
+// getTotalGramsOnDay sums the alcohol stored with a drinking day's entries, so
+// catalog edits don't rewrite history. found is false when the day has no entries.
+func getTotalGramsOnDay(s Store, year, month, day int) (float64, bool, error) {
+	totalGrams := 0.0
+
+	entries, err := GetDrinkingDayEntries(s, year, month, day)
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, entry := range entries {
+		totalGrams += entry.AlcoholGrams
+	}
+
+	return totalGrams, len(entries) > 0, nil
+}
+
+// GetTotalDrinksOnDay calculates the total number of standard drinks consumed on a given
+// drinking day, counted with the selected standard drink definition
+func GetTotalDrinksOnDay(s Store, year, month, day int) (float64, error) {
+	def, err := GetStandardDrink(s)
+	if err != nil {
+		return -1, err
+	}
+
+	totalGrams, drinksFound, err := getTotalGramsOnDay(s, year, month, day)
+	if err != nil {
+		return -1, err
 	}
 
 	// If no drinks were found, return -1 and nil error
@@ -69,16 +173,18 @@ func GetTotalDrinksOnDay(year, month, day int) (float64, error) {
 		return -1, nil
 	}
 
-	return totalDrinks, nil
+	return StandardDrinksFromGrams(totalGrams, def), nil
 }
 
-func GetTotalDrinksToday() (float64, error) {
-	// Get current date
-	now := time.Now()
-	year, month, day := now.Year(), int(now.Month()), now.Day()
+func GetTotalDrinksToday(s Store) (float64, error) {
+	// Get the current drinking day
+	today, err := GetCurrentDrinkingDay(s)
+	if err != nil {
+		return -1, err
+	}
 
 	// Get total drinks for today
-	totalDrinks, err := GetTotalDrinksOnDay(year, month, day)
+	totalDrinks, err := GetTotalDrinksOnDay(s, today.Year, today.Month, today.Day)
 	if err != nil {
 		return -1, err
 	}
@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"errors"
+	"time"
+)
+
+// BodyProfile holds the inputs to the Widmark formula
+type BodyProfile struct {
+	WeightKg        float64 `json:"weight_kg"`
+	Sex             string  `json:"sex"`               // "male" or "female", picks the default body-water factor
+	BodyWaterFactor float64 `json:"body_water_factor"` // Widmark r; 0 uses the default for Sex
+	EliminationRate float64 `json:"elimination_rate"`  // BAC percentage points removed per hour; 0 uses the default
+}
+
+// BACPoint is the estimated blood alcohol concentration (in %) at a Unix time
+type BACPoint struct {
+	Time int64   `json:"time"`
+	BAC  float64 `json:"bac"`
+}
+
+// BACEstimate summarises a BAC curve as seen at a point in time
+type BACEstimate struct {
+	Current           float64    `json:"current"`
+	Peak              float64    `json:"peak"`
+	PeakAt            int64      `json:"peak_at"`
+	SoberAt           int64      `json:"sober_at"` // 0 when no alcohol is in the curve
+	MinutesUntilSober int        `json:"minutes_until_sober"`
+	Curve             []BACPoint `json:"curve"`
+}
+
+const (
+	maleBodyWater          = 0.68
+	femaleBodyWater        = 0.55
+	defaultEliminationRate = 0.015
+
+	// Drinks are assumed to be absorbed evenly over this long
+	absorptionTime = 30 * time.Minute
+
+	// Spacing of the points in an estimated curve
+	bacStep = 5 * time.Minute
+)
+
+// GetBodyProfile returns the saved profile; WeightKg is 0 if none has been saved
+func GetBodyProfile(s Store) (BodyProfile, error) {
+	var profile BodyProfile
+	_, err := loadSetting(s, bodyProfileKey, &profile)
+	return profile, err
+}
+
+// SaveBodyProfile validates and stores the profile
+func SaveBodyProfile(s Store, profile BodyProfile) error {
+	if profile.WeightKg <= 0 {
+		return errors.New("weight must be greater than 0")
+	}
+	if profile.Sex != "male" && profile.Sex != "female" && profile.BodyWaterFactor == 0 {
+		return errors.New("a body water factor is required unless sex is male or female")
+	}
+	if profile.BodyWaterFactor < 0 || profile.BodyWaterFactor > 1 {
+		return errors.New("body water factor must be between 0 and 1")
+	}
+	if profile.EliminationRate < 0 {
+		return errors.New("elimination rate must not be negative")
+	}
+	return saveSetting(s, bodyProfileKey, profile)
+}
+
+// bodyWater returns r, falling back to the default for the profile's sex
+func (p BodyProfile) bodyWater() float64 {
+	if p.BodyWaterFactor > 0 {
+		return p.BodyWaterFactor
+	}
+	if p.Sex == "female" {
+		return femaleBodyWater
+	}
+	return maleBodyWater
+}
+
+func (p BodyProfile) eliminationRate() float64 {
+	if p.EliminationRate > 0 {
+		return p.EliminationRate
+	}
+	return defaultEliminationRate
+}
+
+// EstimateBACCurve applies the Widmark formula, BAC = A / (W * r) - β * t, to a
+// set of entries. Each drink is absorbed linearly over absorptionTime starting
+// at its ConsumedAt time, and elimination only runs while there is alcohol to remove.
+// The curve runs from the first drink until the estimated BAC returns to 0.
+func EstimateBACCurve(entries []DayData, profile BodyProfile) []BACPoint {
+	curve := []BACPoint{}
+	if len(entries) == 0 || profile.WeightKg <= 0 {
+		return curve
+	}
+
+	sorted := append([]DayData(nil), entries...)
+	sortByConsumedAt(sorted)
+
+	// Grams of alcohol to BAC percentage points
+	perGram := 100 / (profile.WeightKg * 1000 * profile.bodyWater())
+	eliminationPerStep := profile.eliminationRate() * bacStep.Hours()
+	lastDrink := sorted[len(sorted)-1].ConsumedAt.Add(absorptionTime)
+
+	bac := 0.0
+	t := sorted[0].ConsumedAt
+	curve = append(curve, BACPoint{Time: t.Unix(), BAC: 0})
+	for {
+		next := t.Add(bacStep)
+		for _, entry := range sorted {
+			bac += entry.AlcoholGrams * perGram * absorbedFraction(entry, t, next)
+		}
+		bac -= eliminationPerStep
+		if bac < 0 {
+			bac = 0
+		}
+
+		t = next
+		curve = append(curve, BACPoint{Time: t.Unix(), BAC: bac})
+		if bac == 0 && !t.Before(lastDrink) {
+			break
+		}
+	}
+	return curve
+}
+
+// absorbedFraction is the share of an entry absorbed between from and to
+func absorbedFraction(entry DayData, from, to time.Time) float64 {
+	start := entry.ConsumedAt
+	end := start.Add(absorptionTime)
+	if !to.After(start) || !from.Before(end) {
+		return 0
+	}
+	if from.Before(start) {
+		from = start
+	}
+	if to.After(end) {
+		to = end
+	}
+	return to.Sub(from).Seconds() / absorptionTime.Seconds()
+}
+
+// SummariseBACCurve reads the current, peak and sober-at values off a curve
+func SummariseBACCurve(curve []BACPoint, at time.Time) BACEstimate {
+	estimate := BACEstimate{Curve: curve}
+	if len(curve) == 0 {
+		return estimate
+	}
+
+	for _, point := range curve {
+		if point.BAC > estimate.Peak {
+			estimate.Peak = point.BAC
+			estimate.PeakAt = point.Time
+		}
+		if point.Time <= at.Unix() {
+			estimate.Current = point.BAC
+		}
+	}
+
+	last := curve[len(curve)-1]
+	if last.Time <= at.Unix() {
+		estimate.Current = 0
+	}
+	if estimate.Peak > 0 {
+		estimate.SoberAt = last.Time
+		if minutes := int(time.Unix(last.Time, 0).Sub(at).Minutes()); minutes > 0 {
+			estimate.MinutesUntilSober = minutes
+		}
+	}
+	return estimate
+}
+
+// EstimateBAC estimates the BAC at a moment from the entries on that day and the
+// day before, so a night out that crosses midnight is still counted
+func EstimateBAC(s Store, at time.Time) (BACEstimate, error) {
+	profile, err := GetBodyProfile(s)
+	if err != nil {
+		return BACEstimate{}, err
+	}
+	if profile.WeightKg <= 0 {
+		return BACEstimate{}, errors.New("body profile has not been set")
+	}
+
+	loc, err := GetLocation(s)
+	if err != nil {
+		return BACEstimate{}, err
+	}
+	at = at.In(loc)
+
+	entries := []DayData{}
+	for _, date := range []time.Time{at.AddDate(0, 0, -1), at} {
+		dayEntries, err := GetEntriesByDateList(s, date.Year(), int(date.Month()), date.Day())
+		if err != nil {
+			return BACEstimate{}, err
+		}
+		entries = append(entries, dayEntries...)
+	}
+
+	// Drinks logged for later in the day haven't been drunk yet
+	consumed := []DayData{}
+	for _, entry := range entries {
+		if !entry.ConsumedAt.After(at) {
+			consumed = append(consumed, entry)
+		}
+	}
+
+	return SummariseBACCurve(EstimateBACCurve(consumed, profile), at), nil
+}
@@ -0,0 +1,125 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// An 80 kg man: 1 g of alcohol raises his BAC by 100 / (80000 * 0.68) points
+var testProfile = BodyProfile{WeightKg: 80, Sex: "male"}
+
+const bacTolerance = 1e-9
+
+func TestWidmarkSingleDrink(t *testing.T) {
+	start := time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)
+	curve := EstimateBACCurve([]DayData{{AlcoholGrams: 28, ConsumedAt: start}}, testProfile)
+
+	// 28 g is 0.051471 points, absorbed over 30 minutes while 0.015 an hour is
+	// eliminated: the peak is 0.051471 - 0.0075 = 0.043971 at 20:30. Clearing that
+	// at 0.00125 every 5 minutes takes 36 steps, so he is sober at 23:30.
+	peak := 28*100/(80000*0.68) - 0.0075
+	estimate := SummariseBACCurve(curve, start.Add(time.Hour))
+	if math.Abs(estimate.Peak-peak) > bacTolerance || estimate.PeakAt != start.Add(30*time.Minute).Unix() {
+		t.Errorf("peak = %g at %d, want %g at 20:30", estimate.Peak, estimate.PeakAt, peak)
+	}
+	if want := peak - 0.0075; math.Abs(estimate.Current-want) > bacTolerance {
+		t.Errorf("BAC at 21:00 = %g, want %g", estimate.Current, want)
+	}
+	if estimate.SoberAt != start.Add(210*time.Minute).Unix() || estimate.MinutesUntilSober != 150 {
+		t.Errorf("sober at %d in %d minutes, want 23:30 in 150", estimate.SoberAt, estimate.MinutesUntilSober)
+	}
+
+	// Before the first drink and after the curve ends nothing is in the blood
+	if estimate := SummariseBACCurve(curve, start.Add(-time.Minute)); estimate.Current != 0 {
+		t.Errorf("BAC before drinking = %g, want 0", estimate.Current)
+	}
+	if estimate := SummariseBACCurve(curve, start.Add(5*time.Hour)); estimate.Current != 0 || estimate.MinutesUntilSober != 0 {
+		t.Errorf("after sobering up, BAC = %g with %d minutes to go, want 0 and 0", estimate.Current, estimate.MinutesUntilSober)
+	}
+}
+
+func TestWidmarkMultipleDrinks(t *testing.T) {
+	start := time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)
+	drinks := []DayData{
+		{AlcoholGrams: 14, ConsumedAt: start.Add(time.Hour)}, // Out of order on purpose
+		{AlcoholGrams: 14, ConsumedAt: start},
+	}
+	estimate := SummariseBACCurve(EstimateBACCurve(drinks, testProfile), start)
+
+	// Each drink adds 0.025735; the first is down to 0.010735 when the second
+	// starts, which then peaks at 0.010735 + 0.025735 - 0.0075 at 21:30
+	perDrink := 14 * 100 / (80000 * 0.68)
+	peak := 2*perDrink - 3*0.0075
+	if math.Abs(estimate.Peak-peak) > bacTolerance || estimate.PeakAt != start.Add(90*time.Minute).Unix() {
+		t.Errorf("peak = %g at %d, want %g at 21:30", estimate.Peak, estimate.PeakAt, peak)
+	}
+
+	// The BAC never reached 0 in between, so the same alcohol clears by the same time as one drink
+	if estimate.SoberAt != start.Add(210*time.Minute).Unix() {
+		t.Errorf("sober at %d, want 23:30 as for a single 28 g drink", estimate.SoberAt)
+	}
+}
+
+func TestWidmarkFloorsAtZero(t *testing.T) {
+	start := time.Date(2024, 3, 5, 18, 0, 0, 0, time.UTC)
+	drinks := []DayData{
+		{AlcoholGrams: 7, ConsumedAt: start},
+		{AlcoholGrams: 7, ConsumedAt: start.Add(5 * time.Hour)},
+	}
+	curve := EstimateBACCurve(drinks, testProfile)
+
+	// Time spent sober between the drinks doesn't bank elimination for the second one
+	first, second := 0.0, 0.0
+	for _, point := range curve {
+		if point.BAC < 0 {
+			t.Fatalf("BAC at %d is negative: %g", point.Time, point.BAC)
+		}
+		if point.Time == start.Add(4*time.Hour).Unix() && point.BAC != 0 {
+			t.Errorf("BAC between the drinks = %g, want 0", point.BAC)
+		}
+		if point.Time < start.Add(5*time.Hour).Unix() {
+			first = math.Max(first, point.BAC)
+		} else {
+			second = math.Max(second, point.BAC)
+		}
+	}
+	if want := 7*100/(80000*0.68) - 0.0075; math.Abs(first-want) > bacTolerance || math.Abs(second-want) > bacTolerance {
+		t.Errorf("peaks = %g and %g, want both %g", first, second, want)
+	}
+	if last := curve[len(curve)-1]; last.BAC != 0 || last.Time <= start.Add(5*time.Hour).Unix() {
+		t.Errorf("the curve ends at %d with %g, want 0 after the second drink", last.Time, last.BAC)
+	}
+}
+
+func TestEstimateBAC(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+		at := time.Date(2024, 3, 5, 0, 30, 0, 0, time.UTC)
+		if _, err := EstimateBAC(s, at); err == nil {
+			t.Error("a BAC was estimated without a body profile")
+		}
+		if err := SaveBodyProfile(s, testProfile); err != nil {
+			t.Fatal(err)
+		}
+
+		// A drink before midnight counts; one logged for later hasn't been drunk yet
+		before := mustAdd(t, s, Date{2024, 3, 4}, DayData{Alcohol: "Vodka", Quantity: 44, ConsumedAt: at.Add(-time.Hour)})
+		mustAdd(t, s, Date{2024, 3, 5}, DayData{Alcohol: "Vodka", Quantity: 44, ConsumedAt: at.Add(time.Hour)})
+
+		estimate, err := EstimateBAC(s, at)
+		if err != nil {
+			t.Fatalf("EstimateBAC: %v", err)
+		}
+		want := SummariseBACCurve(EstimateBACCurve([]DayData{before}, testProfile), at)
+		if estimate.Current != want.Current || estimate.SoberAt != want.SoberAt || estimate.Current == 0 {
+			t.Errorf("estimate = %g sober at %d, want %g sober at %d from the drink before midnight",
+				estimate.Current, estimate.SoberAt, want.Current, want.SoberAt)
+		}
+		if minutes := int(time.Unix(estimate.SoberAt, 0).Sub(at).Minutes()); estimate.MinutesUntilSober != minutes {
+			t.Errorf("%d minutes until sober, want %d", estimate.MinutesUntilSober, minutes)
+		}
+	})
+}
@@ -0,0 +1,327 @@
+package tracker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// BackupFormat identifies a backup file
+	BackupFormat = "alcoholtracker-backup"
+
+	// BackupVersion is the version of the backup file layout written by this build
+	BackupVersion = 1
+)
+
+// Restore modes
+const (
+	RestoreMerge   = "merge"   // Keep what is stored and add what is missing from the backup
+	RestoreReplace = "replace" // Clear the entries, catalog and settings, then load the backup
+)
+
+// Backup is a JSON dump of the entries, catalog and settings (goals included, the
+// API token left out).
+// Entries keep their storage order, so a restore rebuilds the same day buckets.
+type Backup struct {
+	Format        string                     `json:"format"`
+	Version       int                        `json:"version"`
+	SchemaVersion int                        `json:"schema_version"` // Database schema the entries are written in
+	CreatedAt     time.Time                  `json:"created_at"`
+	Entries       []Record                   `json:"entries"`
+	Catalog       []Drink                    `json:"catalog"`
+	Settings      map[string]json.RawMessage `json:"settings"`
+	Checksum      string                     `json:"checksum"` // SHA-256 of the backup with an empty checksum
+}
+
+// RestoreResult counts what a restore wrote
+type RestoreResult struct {
+	Entries         int `json:"entries"`
+	SkippedEntries  int `json:"skipped_entries"` // Entries already stored, in merge mode
+	Drinks          int `json:"drinks"`
+	SkippedDrinks   int `json:"skipped_drinks"`
+	Settings        int `json:"settings"`
+	SkippedSettings int `json:"skipped_settings"`
+}
+
+// checksum hashes the compact JSON of the backup without its checksum
+func (b Backup) checksum() (string, error) {
+	b.Checksum = ""
+	data, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CreateBackup collects everything in the store into a checksummed backup
+func CreateBackup(s Store) (Backup, error) {
+	backup := Backup{
+		Format:        BackupFormat,
+		Version:       BackupVersion,
+		SchemaVersion: CurrentSchemaVersion(),
+		CreatedAt:     time.Now(),
+		Settings:      map[string]json.RawMessage{},
+	}
+
+	var err error
+	backup.Entries, err = s.GetRecordsInRange(firstTrackedDate, lastTrackedDate)
+	if err != nil {
+		return backup, err
+	}
+
+	backup.Catalog, err = s.GetDrinks()
+	if err != nil {
+		return backup, err
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return backup, err
+	}
+	for key, value := range settings {
+		if localSettings[key] {
+			continue
+		}
+		if !json.Valid(value) {
+			return backup, fmt.Errorf("setting %s is not valid JSON", key)
+		}
+		backup.Settings[key] = value
+	}
+
+	backup.Checksum, err = backup.checksum()
+	return backup, err
+}
+
+// WriteBackup writes a backup of the store to w as indented JSON
+func WriteBackup(s Store, w io.Writer) (Backup, error) {
+	backup, err := CreateBackup(s)
+	if err != nil {
+		return backup, err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return backup, encoder.Encode(backup)
+}
+
+// ReadBackup parses a backup and checks its format, version and checksum
+func ReadBackup(r io.Reader) (Backup, error) {
+	var backup Backup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return backup, fmt.Errorf("invalid backup: %v", err)
+	}
+
+	if backup.Format != BackupFormat {
+		return backup, errors.New("not an AlcoholTracker backup")
+	}
+	if backup.Version > BackupVersion {
+		return backup, fmt.Errorf("backup version %d is newer than this build supports (%d)", backup.Version, BackupVersion)
+	}
+
+	// Indentation is not part of a value, and the checksum is over compact JSON
+	for key, value := range backup.Settings {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, value); err != nil {
+			return backup, fmt.Errorf("invalid setting %s: %v", key, err)
+		}
+		backup.Settings[key] = compact.Bytes()
+	}
+
+	sum, err := backup.checksum()
+	if err != nil {
+		return backup, err
+	}
+	if sum != backup.Checksum {
+		return backup, errors.New("backup checksum does not match; the file is damaged or was edited")
+	}
+	return backup, nil
+}
+
+// RestoreBackup loads a backup into the store in RestoreMerge or RestoreReplace mode.
+// Backups made with an older schema are migrated first; newer ones are refused.
+func RestoreBackup(s Store, backup Backup, mode string) (RestoreResult, error) {
+	if mode != RestoreMerge && mode != RestoreReplace {
+		return RestoreResult{}, fmt.Errorf("unknown restore mode '%s'", mode)
+	}
+
+	// Entries are stored as they were written, so older ones are brought up to this build's schema
+	if backup.SchemaVersion > CurrentSchemaVersion() {
+		return RestoreResult{}, fmt.Errorf("backup was made with database schema %d, which is newer than this build's schema %d",
+			backup.SchemaVersion, CurrentSchemaVersion())
+	}
+	if backup.SchemaVersion < CurrentSchemaVersion() {
+		upgraded, err := upgradeBackup(backup)
+		if err != nil {
+			return RestoreResult{}, fmt.Errorf("failed to upgrade backup from schema %d: %v", backup.SchemaVersion, err)
+		}
+		backup = upgraded
+	}
+
+	// Backups made before the token was left out may still carry one
+	settings := make(map[string]json.RawMessage, len(backup.Settings))
+	for key, value := range backup.Settings {
+		if !localSettings[key] {
+			settings[key] = value
+		}
+	}
+	backup.Settings = settings
+
+	for i, record := range backup.Entries {
+		if err := ValidateDate(record.Date.Day, record.Date.Month, record.Date.Year); err != nil {
+			return RestoreResult{}, fmt.Errorf("entry %d: %v", i+1, err)
+		}
+		if record.Entry.ID == "" {
+			return RestoreResult{}, fmt.Errorf("entry %d has no ID", i+1)
+		}
+	}
+
+	return s.Restore(backup, mode == RestoreReplace)
+}
+
+// mergeSetting combines a stored settings value with the one from a backup in
+// merge mode. Goals and their history are merged by goal ID, keeping the stored
+// ones; any other setting that is already stored is kept as it is. changed
+// reports whether the merged value differs from the stored one.
+func mergeSetting(key string, stored, restored []byte) (merged []byte, changed bool, err error) {
+	switch key {
+	case goalsKey:
+		var current, backedUp []Goal
+		if err := json.Unmarshal(stored, &current); err != nil {
+			return nil, false, err
+		}
+		if err := json.Unmarshal(restored, &backedUp); err != nil {
+			return nil, false, err
+		}
+
+		known := make(map[string]bool, len(current))
+		for _, goal := range current {
+			known[goal.ID] = true
+		}
+		for _, goal := range backedUp {
+			if !known[goal.ID] {
+				known[goal.ID] = true
+				current = append(current, goal)
+				changed = true
+			}
+		}
+		if !changed {
+			return stored, false, nil
+		}
+		merged, err = json.Marshal(current)
+		return merged, err == nil, err
+
+	case goalHistoryKey:
+		current := map[string][]GoalResult{}
+		backedUp := map[string][]GoalResult{}
+		if err := json.Unmarshal(stored, &current); err != nil {
+			return nil, false, err
+		}
+		if err := json.Unmarshal(restored, &backedUp); err != nil {
+			return nil, false, err
+		}
+
+		for id, results := range backedUp {
+			if _, ok := current[id]; !ok {
+				current[id] = results
+				changed = true
+			}
+		}
+		if !changed {
+			return stored, false, nil
+		}
+		merged, err = json.Marshal(current)
+		return merged, err == nil, err
+	}
+
+	return stored, false, nil
+}
+
+// upgradeBackup runs the migrations on a backup made with an older schema. It is
+// written to a scratch database marked with the backup's schema version, migrated
+// there as InitDB would migrate a file, and read back.
+func upgradeBackup(backup Backup) (Backup, error) {
+	dir, err := os.MkdirTemp("", "alcoholtracker-restore")
+	if err != nil {
+		return backup, err
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := bbolt.Open(filepath.Join(dir, DefaultDBFile), 0600, nil)
+	if err != nil {
+		return backup, err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if err := setSchemaVersion(tx, backup.SchemaVersion); err != nil {
+			return err
+		}
+
+		for _, record := range backup.Entries {
+			date := record.Date
+			dayBucket, err := createDayBucket(tx, date.Year, date.Month, date.Day)
+			if err != nil {
+				return err
+			}
+			entries, err := readCategory(dayBucket, record.Category)
+			if err != nil {
+				return err
+			}
+			if err := writeCategory(dayBucket, record.Category, append(entries, record.Entry)); err != nil {
+				return err
+			}
+		}
+
+		// Schemas before the catalog have none, and the migrations seed it
+		for _, drink := range backup.Catalog {
+			if err := putDrink(tx, drink); err != nil {
+				return err
+			}
+		}
+
+		if len(backup.Settings) == 0 {
+			return nil
+		}
+		settings, err := tx.CreateBucketIfNotExists(settingsBucket)
+		if err != nil {
+			return err
+		}
+		for key, value := range backup.Settings {
+			if err := settings.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return backup, err
+	}
+
+	if _, err := Migrate(db, "", false); err != nil {
+		return backup, err
+	}
+
+	upgraded, err := CreateBackup(&BoltStore{db: db})
+	upgraded.CreatedAt = backup.CreatedAt
+	return upgraded, err
+}
+
+// RestoreBackupFile reads, checks and restores a backup in one step
+func RestoreBackupFile(s Store, r io.Reader, mode string) (RestoreResult, error) {
+	backup, err := ReadBackup(r)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	return RestoreBackup(s, backup, mode)
+}
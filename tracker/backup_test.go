@@ -0,0 +1,266 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seedBackupStore fills a store with an entry, a custom drink and a setting
+func seedBackupStore(t *testing.T, s Store) DayData {
+	t.Helper()
+	if err := SaveDrink(s, Drink{Name: "Cider", ABV: 4.5, ServingML: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDayRolloverHour(s, 5); err != nil {
+		t.Fatal(err)
+	}
+	return mustAdd(t, s, Date{Year: 2024, Month: 3, Day: 5}, DayData{Alcohol: "Cider", Quantity: 500, Cost: 450})
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		entry := seedBackupStore(t, s)
+
+		var file bytes.Buffer
+		if _, err := WriteBackup(s, &file); err != nil {
+			t.Fatalf("WriteBackup: %v", err)
+		}
+
+		target := NewMemoryStore()
+		result, err := RestoreBackupFile(target, bytes.NewReader(file.Bytes()), RestoreReplace)
+		if err != nil {
+			t.Fatalf("RestoreBackupFile: %v", err)
+		}
+		if result.Entries != 1 || result.SkippedEntries != 0 {
+			t.Errorf("restored %+v, want the one entry", result)
+		}
+
+		restored, err := target.GetEntry(2024, 3, 5, entry.ID)
+		if err != nil {
+			t.Fatalf("GetEntry: %v", err)
+		}
+		if restored.Alcohol != "Cider" || restored.ABV != 4.5 || restored.Cost != 450 || !restored.ConsumedAt.Equal(entry.ConsumedAt) {
+			t.Errorf("restored %+v, want %+v", restored, entry)
+		}
+		if hour, _ := GetDayRolloverHour(target); hour != 5 {
+			t.Errorf("rollover hour = %d, want 5", hour)
+		}
+		if catalog, _ := LoadCatalog(target); catalog["Cider"].ABV != 4.5 {
+			t.Error("the catalog was not restored")
+		}
+	})
+}
+
+func TestReadBackupDetectsTampering(t *testing.T) {
+	s := NewMemoryStore()
+	seedBackupStore(t, s)
+
+	var file bytes.Buffer
+	if _, err := WriteBackup(s, &file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBackup(bytes.NewReader(file.Bytes())); err != nil {
+		t.Fatalf("ReadBackup of an untouched file: %v", err)
+	}
+
+	edited := strings.Replace(file.String(), `"quantity": 500`, `"quantity": 50`, 1)
+	if edited == file.String() {
+		t.Fatal("the test edit did not apply")
+	}
+	if _, err := ReadBackup(strings.NewReader(edited)); err == nil {
+		t.Error("ReadBackup accepted an edited file")
+	}
+
+	if _, err := ReadBackup(strings.NewReader(`{"format": "something-else"}`)); err == nil {
+		t.Error("ReadBackup accepted a file in another format")
+	}
+}
+
+func TestRestoreBackupModes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		source := NewMemoryStore()
+		backedUp := seedBackupStore(t, source)
+		backup, err := CreateBackup(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		kept := mustAdd(t, s, Date{Year: 2024, Month: 3, Day: 5}, DayData{Alcohol: "Beer", Quantity: 330})
+		if err := SetDayRolloverHour(s, 3); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := RestoreBackup(s, backup, "overwrite"); err == nil {
+			t.Error("an unknown restore mode was accepted")
+		}
+
+		// Merging keeps what is stored and skips what is already there
+		if _, err := RestoreBackup(s, backup, RestoreMerge); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		result, err := RestoreBackup(s, backup, RestoreMerge)
+		if err != nil || result.Entries != 0 || result.SkippedEntries != 1 {
+			t.Errorf("second merge = %+v, %v; want the entry skipped", result, err)
+		}
+		entries, _ := GetEntriesByDateList(s, 2024, 3, 5)
+		if len(entries) != 2 {
+			t.Errorf("after merging, %d entries are stored, want 2", len(entries))
+		}
+		if hour, _ := GetDayRolloverHour(s); hour != 3 {
+			t.Errorf("merging replaced the rollover hour with %d", hour)
+		}
+
+		// Replacing leaves only the backup
+		if _, err := RestoreBackup(s, backup, RestoreReplace); err != nil {
+			t.Fatalf("replace: %v", err)
+		}
+		if _, err := s.GetEntry(2024, 3, 5, kept.ID); err == nil {
+			t.Error("replacing kept an entry missing from the backup")
+		}
+		if _, err := s.GetEntry(2024, 3, 5, backedUp.ID); err != nil {
+			t.Errorf("replacing lost the backed up entry: %v", err)
+		}
+		if hour, _ := GetDayRolloverHour(s); hour != 5 {
+			t.Errorf("rollover hour = %d after replacing, want the backup's 5", hour)
+		}
+	})
+}
+
+func TestRestoreMergeSkipsMovedEntries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		entry := seedBackupStore(t, s)
+		backup, err := CreateBackup(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := UpdateEntry(s, 2024, 3, 5, entry.ID, 2024, 3, 6, entry); err != nil {
+			t.Fatalf("UpdateEntry: %v", err)
+		}
+
+		result, err := RestoreBackup(s, backup, RestoreMerge)
+		if err != nil || result.Entries != 0 || result.SkippedEntries != 1 {
+			t.Errorf("merge = %+v, %v; want the moved entry skipped", result, err)
+		}
+		if entries, _ := GetEntriesByDateList(s, 2024, 3, 5); len(entries) != 0 {
+			t.Errorf("merging put %d entries back on the old date, want 0", len(entries))
+		}
+		if _, err := s.GetEntry(2024, 3, 6, entry.ID); err != nil {
+			t.Errorf("the moved entry is gone: %v", err)
+		}
+	})
+}
+
+func TestRestoreMergeGoals(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		source := NewMemoryStore()
+		backedUp, err := SaveGoal(source, Goal{Kind: GoalMaxDrinks, Period: PeriodWeek, Target: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		backup, err := CreateBackup(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stored, err := SaveGoal(s, Goal{Kind: GoalMinAlcoholFreeDays, Period: PeriodMonth, Target: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, err := RestoreBackup(s, backup, RestoreMerge); err != nil {
+				t.Fatalf("merge %d: %v", i+1, err)
+			}
+		}
+		goals, err := GetGoals(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(goals) != 2 || goals[0].ID != stored.ID || goals[1].ID != backedUp.ID {
+			t.Errorf("goals after merging = %+v, want the stored goal and then the backed up one", goals)
+		}
+	})
+}
+
+func TestRestoreOlderBackup(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		// A backup as the first schema wrote it: no IDs, catalog, ABVs or currencies
+		consumed := time.Date(2023, 6, 10, 21, 0, 0, 0, time.Local)
+		backup := Backup{
+			Format:        BackupFormat,
+			Version:       BackupVersion,
+			SchemaVersion: 0,
+			Entries: []Record{{
+				Date:     Date{Year: 2023, Month: 6, Day: 10},
+				Category: "Beer",
+				Entry:    DayData{Alcohol: "Beer", Quantity: 500, LegacyCost: 6.5, Timestamp: consumed.Unix()},
+			}},
+			Catalog:  []Drink{},
+			Settings: map[string]json.RawMessage{monthlyBudgetKey: json.RawMessage("100.5")},
+		}
+
+		result, err := RestoreBackup(s, backup, RestoreReplace)
+		if err != nil {
+			t.Fatalf("RestoreBackup: %v", err)
+		}
+		if result.Entries != 1 || result.Drinks != len(defaultDrinks) {
+			t.Errorf("restored %+v, want the entry and the seeded catalog", result)
+		}
+
+		entries, _ := GetEntriesByDateList(s, 2023, 6, 10)
+		if len(entries) != 1 {
+			t.Fatalf("%d entries restored, want 1", len(entries))
+		}
+		entry := entries[0]
+		if entry.ID == "" || entry.ABV != 5 || entry.Cost != 650 || entry.Currency != "USD" || !entry.ConsumedAt.Equal(consumed) {
+			t.Errorf("restored %+v, want it migrated to the current schema", entry)
+		}
+
+		budget := Money{}
+		if _, err := loadSetting(s, monthlyBudgetKey, &budget); err != nil || budget.Amount != 10050 {
+			t.Errorf("monthly budget = %+v, %v; want 10050 USD", budget, err)
+		}
+
+		backup.SchemaVersion = CurrentSchemaVersion() + 1
+		if _, err := RestoreBackup(s, backup, RestoreMerge); err == nil {
+			t.Error("a backup from a newer schema was restored")
+		}
+	})
+}
+
+func TestBackupKeepsAPIToken(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		source := NewMemoryStore()
+		seedBackupStore(t, source)
+		if _, err := GetAPIToken(source); err != nil {
+			t.Fatal(err)
+		}
+		backup, err := CreateBackup(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := backup.Settings[apiTokenKey]; ok {
+			t.Error("the backup holds the API token")
+		}
+
+		token, err := GetAPIToken(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Older backups may still carry a token, which must not be restored
+		backup.Settings[apiTokenKey] = json.RawMessage(`"old"`)
+		for _, mode := range []string{RestoreReplace, RestoreMerge} {
+			if _, err := RestoreBackup(s, backup, mode); err != nil {
+				t.Fatalf("%s: %v", mode, err)
+			}
+			if restored, err := GetAPIToken(s); err != nil || restored != token {
+				t.Errorf("after a %s restore the token is %q, %v; want it kept", mode, restored, err)
+			}
+		}
+	})
+}
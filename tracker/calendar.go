@@ -3,7 +3,11 @@ package tracker
 import (
 	"errors"
 	"fmt"
+	"log"
 	"time"
+
+	// Zone names must resolve on systems without a zoneinfo database, such as Windows
+	_ "time/tzdata"
 )
 
 func ValidateDate(day, month, year int) error {
@@ -17,3 +21,225 @@ func ValidateDate(day, month, year int) error {
 	}
 	return nil
 }
+
+// Date is a calendar or drinking day without a time
+type Date struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// DateOf returns the date part of t
+func DateOf(t time.Time) Date {
+	return Date{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}
+}
+
+// String formats the date as YYYY-MM-DD
+func (d Date) String() string {
+	return fmt.Sprintf("%d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Before reports whether d is an earlier date than other
+func (d Date) Before(other Date) bool {
+	return d.String() < other.String()
+}
+
+// Time returns the date as a UTC midnight
+func (d Date) Time() time.Time {
+	return time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// WeekOf returns the Monday and Sunday of the week holding d
+func WeekOf(d Date) (Date, Date) {
+	t := d.Time()
+	offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+	monday := t.AddDate(0, 0, -offset)
+	return DateOf(monday), DateOf(monday.AddDate(0, 0, 6))
+}
+
+// GetTimezone returns the saved IANA time zone name, or "" when the system zone is used
+func GetTimezone(s Store) (string, error) {
+	name := ""
+	_, err := loadSetting(s, timezoneKey, &name)
+	return name, err
+}
+
+// SetTimezone saves an IANA time zone name such as "Europe/Berlin"; "" selects the system zone
+func SetTimezone(s Store, name string) error {
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown time zone '%s'", name)
+	}
+	return saveSetting(s, timezoneKey, name)
+}
+
+// GetLocation returns the user's time zone, which all "today" and day-count math is done in.
+// A saved zone that can't be loaded is logged and the system zone used instead.
+func GetLocation(s Store) (*time.Location, error) {
+	name, err := GetTimezone(s)
+	if err != nil || name == "" {
+		return time.Local, err
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Failed to load time zone %s, using the system zone: %v", name, err)
+		return time.Local, nil
+	}
+	return loc, nil
+}
+
+// GetCurrentDrinkingDay returns the drinking day in progress now in the user's time zone
+func GetCurrentDrinkingDay(s Store) (Date, error) {
+	loc, err := GetLocation(s)
+	if err != nil {
+		return DateOf(time.Now()), err
+	}
+
+	rollover, err := GetDayRolloverHour(s)
+	if err != nil {
+		return DateOf(time.Now()), err
+	}
+	return DateOf(DrinkingDayOf(time.Now(), rollover, loc)), nil
+}
+
+// GetDayRolloverHour returns the hour at which one drinking day ends and the next begins
+func GetDayRolloverHour(s Store) (int, error) {
+	hour := 0
+	_, err := loadSetting(s, dayRolloverKey, &hour)
+	return hour, err
+}
+
+// SetDayRolloverHour sets the hour at which one drinking day ends and the next begins.
+// With 5, drinks until 04:59 count towards the previous day.
+func SetDayRolloverHour(s Store, hour int) error {
+	if hour < 0 || hour > 12 {
+		return errors.New("day rollover hour must be between 0 and 12")
+	}
+	return saveSetting(s, dayRolloverKey, hour)
+}
+
+// DrinkingDayOf returns the drinking day a moment belongs to, judged by the wall
+// clock in loc, as a UTC midnight. Comparing wall clock hours rather than
+// subtracting a duration keeps the rollover on time across DST changes.
+func DrinkingDayOf(t time.Time, rollover int, loc *time.Location) time.Time {
+	local := t.In(loc)
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	if local.Hour() < rollover {
+		return date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// entryDrinkingDay returns the drinking day of an entry stored on a calendar date.
+// An entry consumed on that date before the rollover hour belongs to the day before.
+// ConsumedAt is read in the zone it was logged in, so drinks had while travelling
+// follow the local clock where they were had.
+func entryDrinkingDay(year, month, day int, entry DayData, rollover int) time.Time {
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+	consumed := entry.ConsumedAt
+	if consumed.Year() == year && int(consumed.Month()) == month && consumed.Day() == day && consumed.Hour() < rollover {
+		return date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// ConsumedAtOn builds a consumption time on a date from an "HH:MM" time of day in loc.
+// An empty time of day means now for today's date, and noon for any other date.
+func ConsumedAtOn(year, month, day int, timeOfDay string, loc *time.Location) (time.Time, error) {
+	if timeOfDay == "" {
+		now := time.Now().In(loc)
+		if now.Year() == year && int(now.Month()) == month && now.Day() == day {
+			return now, nil
+		}
+		return time.Date(year, time.Month(month), day, 12, 0, 0, 0, loc), nil
+	}
+
+	clock, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time of day '%s': expected HH:MM", timeOfDay)
+	}
+	return time.Date(year, time.Month(month), day, clock.Hour(), clock.Minute(), 0, 0, loc), nil
+}
+
+// GetDrinkingDayEntries returns the entries attributed to a drinking day: those on
+// its calendar date from the rollover hour on, and those after midnight on the
+// next date that fall before the rollover hour.
+func GetDrinkingDayEntries(s Store, year, month, day int) ([]DayData, error) {
+	rollover, err := GetDayRolloverHour(s)
+	if err != nil {
+		return []DayData{}, err
+	}
+
+	target := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	entries := []DayData{}
+	for _, date := range []time.Time{target, target.AddDate(0, 0, 1)} {
+		if rollover == 0 && !date.Equal(target) {
+			break
+		}
+
+		dayEntries, err := GetEntriesByDateList(s, date.Year(), int(date.Month()), date.Day())
+		if err != nil {
+			return []DayData{}, err
+		}
+
+		for _, entry := range dayEntries {
+			if entryDrinkingDay(date.Year(), int(date.Month()), date.Day(), entry, rollover).Equal(target) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// latestDrinkingDay returns the most recent drinking day holding any entry
+func latestDrinkingDay(s Store, rollover int) (time.Time, error) {
+	latestYear, latestMonth, latestDay, err := s.FindLatestEntryDate()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	latest := time.Date(latestYear, time.Month(latestMonth), latestDay, 0, 0, 0, 0, time.UTC)
+	entries, err := GetEntriesByDateList(s, latestYear, latestMonth, latestDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// If every entry on the latest date was before the rollover, the night started the day before
+	if len(entries) > 0 {
+		previous := latest.AddDate(0, 0, -1)
+		for _, entry := range entries {
+			if entryDrinkingDay(latestYear, latestMonth, latestDay, entry, rollover).After(previous) {
+				return latest, nil
+			}
+		}
+		return previous, nil
+	}
+	return latest, nil
+}
+
+// GetDaysSinceLastEntry calculates the number of drinking days since the latest entry,
+// with "today" taken in the user's time zone.
+func GetDaysSinceLastEntry(s Store) (int, error) {
+	loc, err := GetLocation(s)
+	if err != nil {
+		return -1, err
+	}
+
+	rollover, err := GetDayRolloverHour(s)
+	if err != nil {
+		return -1, err
+	}
+
+	latestDate, err := latestDrinkingDay(s, rollover)
+	if err != nil {
+		return -1, err
+	}
+
+	// Both are UTC midnights, so the difference is a whole number of days
+	today := DrinkingDayOf(time.Now(), rollover, loc)
+	daysSince := int(today.Sub(latestDate).Hours() / 24)
+
+	return daysSince, nil
+}
@@ -0,0 +1,103 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetLocation(t *testing.T) {
+	s := NewMemoryStore()
+	if loc, err := GetLocation(s); err != nil || loc != time.Local {
+		t.Errorf("GetLocation without a zone = %v, %v; want the system zone", loc, err)
+	}
+
+	if err := SetTimezone(s, "Asia/Seoul"); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+	if loc, err := GetLocation(s); err != nil || loc.String() != "Asia/Seoul" {
+		t.Errorf("GetLocation = %v, %v; want Asia/Seoul", loc, err)
+	}
+
+	if err := SetTimezone(s, "Mars/Olympus_Mons"); err == nil {
+		t.Error("SetTimezone accepted an unknown zone")
+	}
+
+	// A zone saved by a build that knew it falls back rather than failing
+	if err := saveSetting(s, timezoneKey, "Mars/Olympus_Mons"); err != nil {
+		t.Fatal(err)
+	}
+	if loc, err := GetLocation(s); err != nil || loc != time.Local {
+		t.Errorf("GetLocation with an unloadable zone = %v, %v; want the system zone", loc, err)
+	}
+}
+
+func TestDrinkingDayOf(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want Date
+	}{
+		{time.Date(2024, 3, 5, 4, 59, 0, 0, loc), Date{Year: 2024, Month: 3, Day: 4}},
+		{time.Date(2024, 3, 5, 5, 0, 0, 0, loc), Date{Year: 2024, Month: 3, Day: 5}},
+		{time.Date(2024, 3, 5, 4, 30, 0, 0, time.UTC), Date{Year: 2024, Month: 3, Day: 5}}, // 05:30 in Berlin
+		{time.Date(2024, 3, 31, 4, 30, 0, 0, loc), Date{Year: 2024, Month: 3, Day: 30}},    // DST starts at 02:00
+	}
+	for _, tt := range tests {
+		if got := DateOf(DrinkingDayOf(tt.at, 5, loc)); got != tt.want {
+			t.Errorf("DrinkingDayOf(%v) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
+
+// addSession logs a beer at 23:00 on d and two more at 00:30 and 02:00 the next morning
+func addSession(t *testing.T, s Store, d Date) {
+	t.Helper()
+	start := time.Date(d.Year, time.Month(d.Month), d.Day, 23, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{start, start.Add(90 * time.Minute), start.Add(3 * time.Hour)} {
+		mustAdd(t, s, DateOf(at), DayData{Alcohol: "Beer", Quantity: 355, ConsumedAt: at})
+	}
+}
+
+func TestSessionPastMidnightIsOneDay(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetDayRolloverHour(s, 5); err != nil {
+			t.Fatal(err)
+		}
+		addSession(t, s, Date{2024, 3, 4})
+
+		stats, err := GetStats(s, Date{2024, 3, 4}, Date{2024, 3, 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		grams := 3 * AlcoholGrams(355, 5)
+		if stats.DrinkingDays != 1 || stats.AlcoholFreeDays != 1 || stats.Entries != 3 || stats.AlcoholGrams != grams {
+			t.Errorf("stats = %d drinking days, %d alcohol-free, %d entries, %g g; want 1, 1, 3 and %g",
+				stats.DrinkingDays, stats.AlcoholFreeDays, stats.Entries, stats.AlcoholGrams, grams)
+		}
+
+		// Just over 42 g makes a binge, where either calendar date alone would be lower
+		if tier, err := ClassifyDay(s, 2024, 3, 4); err != nil || tier.Name != "binge" {
+			t.Errorf("the 4th is %+v, %v; want binge", tier, err)
+		}
+		if tier, err := ClassifyDay(s, 2024, 3, 5); err != nil || tier.Index != 0 {
+			t.Errorf("the 5th is %+v, %v; want no drinks", tier, err)
+		}
+
+		// A session that ended early this morning was yesterday's
+		today, err := GetCurrentDrinkingDay(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addSession(t, s, daysBefore(today, 1))
+		if days, err := GetDaysSinceLastEntry(s); err != nil || days != 1 {
+			t.Errorf("GetDaysSinceLastEntry = %d, %v; want 1", days, err)
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package tracker
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader is the column order written by ExportCSV
+var csvHeader = []string{"date", "time", "drink", "ml", "abv", "standard_drinks", "cost", "currency", "id"}
+
+// lastTrackedDate is the latest date ValidateDate accepts
+var lastTrackedDate = Date{Year: 2100, Month: 12, Day: 31}
+
+// RowError is a CSV row that could not be imported
+type RowError struct {
+	Row     int    `json:"row"` // 1-based line number, counting the header
+	Message string `json:"message"`
+}
+
+// ImportResult summarises a CSV import
+type ImportResult struct {
+	Imported   int        `json:"imported"`
+	Duplicates int        `json:"duplicates"` // Rows matching an entry that was already stored
+	Errors     []RowError `json:"errors"`
+}
+
+// ExportCSV writes every stored entry to w as CSV, oldest first, and returns the
+// number of rows written. Times are the wall clock where each drink was had and
+// standard drinks use the selected definition.
+func ExportCSV(s Store, w io.Writer) (int, error) {
+	def, err := GetStandardDrink(s)
+	if err != nil {
+		return 0, err
+	}
+
+	records, err := GetEntriesInRange(s, firstTrackedDate.Time(), lastTrackedDate.Time())
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return 0, err
+	}
+
+	for _, record := range records {
+		entry := record.Entry
+		cost := strconv.FormatInt(entry.Cost, 10)
+		if currency, err := LookupCurrency(entry.Currency); err == nil {
+			cost = strconv.FormatFloat(currency.FromMinor(entry.Cost), 'f', currency.Decimals, 64)
+		}
+
+		err := writer.Write([]string{
+			record.Date.String(),
+			entry.ConsumedAt.Format("15:04"),
+			record.Category,
+			strconv.FormatFloat(entry.Quantity, 'f', -1, 64),
+			strconv.FormatFloat(entry.ABV, 'f', -1, 64),
+			strconv.FormatFloat(StandardDrinksFromGrams(entry.AlcoholGrams, def), 'f', 2, 64),
+			cost,
+			entry.Currency,
+			entry.ID,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	writer.Flush()
+	return len(records), writer.Error()
+}
+
+// ImportCSV adds the rows of a CSV file with a header row naming its columns.
+// date, drink and ml are required; time, abv, cost, currency and id are optional,
+// though rows for drinks missing from the catalog need an abv. Other columns
+// (such as standard_drinks) are ignored. A row is skipped as a
+// duplicate when an entry with its id, or with the same drink, time, volume and
+// cost, is already stored on its date. Rows with errors are reported and skipped
+// without stopping the import.
+func ImportCSV(s Store, r io.Reader) (ImportResult, error) {
+	result := ImportResult{Errors: []RowError{}}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return result, errors.New("the file is empty")
+	}
+	if err != nil {
+		return result, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "drink", "ml"} {
+		if _, ok := columns[required]; !ok {
+			return result, fmt.Errorf("missing required column '%s'", required)
+		}
+	}
+
+	loc, err := GetLocation(s)
+	if err != nil {
+		return result, err
+	}
+
+	defaultCurrency, err := GetDefaultCurrency(s)
+	if err != nil {
+		return result, err
+	}
+
+	for row := 2; ; row++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+
+		date, entry, err := parseCSVRow(field, loc, defaultCurrency)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+
+		existing, err := GetEntriesByDateList(s, date.Year, date.Month, date.Day)
+		if err != nil {
+			return result, err
+		}
+		if isDuplicate(existing, entry) {
+			result.Duplicates++
+			continue
+		}
+
+		if err := AddEntry(s, date.Year, date.Month, date.Day, entry); err != nil {
+			result.Errors = append(result.Errors, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+// parseCSVRow validates one row and builds the entry it describes
+func parseCSVRow(field func(string) string, loc *time.Location, defaultCurrency Currency) (Date, DayData, error) {
+	parsed, err := time.Parse("2006-01-02", field("date"))
+	if err != nil {
+		return Date{}, DayData{}, fmt.Errorf("invalid date '%s': expected YYYY-MM-DD", field("date"))
+	}
+	date := DateOf(parsed)
+	if err := ValidateDate(date.Day, date.Month, date.Year); err != nil {
+		return Date{}, DayData{}, err
+	}
+
+	entry := DayData{ID: field("id"), Alcohol: field("drink")}
+	if entry.Alcohol == "" {
+		return Date{}, DayData{}, errors.New("drink is empty")
+	}
+
+	entry.Quantity, err = strconv.ParseFloat(field("ml"), 64)
+	if err != nil || entry.Quantity <= 0 {
+		return Date{}, DayData{}, fmt.Errorf("invalid ml '%s': expected a number above 0", field("ml"))
+	}
+
+	if abv := field("abv"); abv != "" {
+		entry.ABV, err = strconv.ParseFloat(abv, 64)
+		if err != nil || entry.ABV < 0 || entry.ABV > 100 {
+			return Date{}, DayData{}, fmt.Errorf("invalid abv '%s': expected a percentage", abv)
+		}
+	}
+
+	// Rows without a time are placed at noon, as for drinks logged on past dates
+	timeOfDay := field("time")
+	if timeOfDay == "" {
+		timeOfDay = "12:00"
+	}
+	entry.ConsumedAt, err = ConsumedAtOn(date.Year, date.Month, date.Day, timeOfDay, loc)
+	if err != nil {
+		return Date{}, DayData{}, err
+	}
+
+	currency := defaultCurrency
+	if code := field("currency"); code != "" {
+		currency, err = LookupCurrency(code)
+		if err != nil {
+			return Date{}, DayData{}, err
+		}
+	}
+	entry.Currency = currency.Code
+
+	if cost := field("cost"); cost != "" {
+		amount, err := strconv.ParseFloat(cost, 64)
+		if err != nil || amount < 0 {
+			return Date{}, DayData{}, fmt.Errorf("invalid cost '%s': expected a number", cost)
+		}
+		entry.Cost = currency.ToMinor(amount)
+	}
+
+	return date, entry, nil
+}
+
+// isDuplicate reports whether entry is already among the entries stored on its date
+func isDuplicate(existing []DayData, entry DayData) bool {
+	for _, stored := range existing {
+		if entry.ID != "" && stored.ID == entry.ID {
+			return true
+		}
+		if stored.Alcohol == entry.Alcohol &&
+			stored.ConsumedAt.Format("15:04") == entry.ConsumedAt.Format("15:04") &&
+			math.Abs(stored.Quantity-entry.Quantity) < 0.01 &&
+			stored.Cost == entry.Cost && stored.Currency == entry.Currency {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package tracker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	source := NewMemoryStore()
+	if err := SetTimezone(source, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, source, Date{Year: 2024, Month: 3, Day: 5}, DayData{
+		Alcohol: "Beer", Quantity: 500, Cost: 650, Currency: "EUR",
+		ConsumedAt: time.Date(2024, 3, 5, 20, 15, 0, 0, time.UTC),
+	})
+	mustAdd(t, source, Date{Year: 2024, Month: 3, Day: 6}, DayData{
+		Alcohol: "Wine", Quantity: 150, ABV: 13.5, Cost: 1200, Currency: "JPY",
+		ConsumedAt: time.Date(2024, 3, 6, 19, 0, 0, 0, time.UTC),
+	})
+
+	var csv bytes.Buffer
+	if n, err := ExportCSV(source, &csv); err != nil || n != 2 {
+		t.Fatalf("ExportCSV = %d, %v; want 2 rows", n, err)
+	}
+
+	target := NewMemoryStore()
+	if err := SetTimezone(target, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportCSV(target, bytes.NewReader(csv.Bytes()))
+	if err != nil || result.Imported != 2 || len(result.Errors) != 0 {
+		t.Fatalf("ImportCSV = %+v, %v; want 2 rows imported", result, err)
+	}
+
+	want, _ := source.GetRecordsInRange(firstTrackedDate, lastTrackedDate)
+	got, _ := target.GetRecordsInRange(firstTrackedDate, lastTrackedDate)
+	if len(got) != len(want) {
+		t.Fatalf("imported %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		w, g := want[i].Entry, got[i].Entry
+		if g.ID != w.ID || g.Alcohol != w.Alcohol || g.Quantity != w.Quantity || g.ABV != w.ABV ||
+			g.Cost != w.Cost || g.Currency != w.Currency || !g.ConsumedAt.Equal(w.ConsumedAt) {
+			t.Errorf("record %d = %+v, want %+v", i, g, w)
+		}
+	}
+
+	// Importing the same file again adds nothing
+	result, err = ImportCSV(target, bytes.NewReader(csv.Bytes()))
+	if err != nil || result.Imported != 0 || result.Duplicates != 2 {
+		t.Errorf("second ImportCSV = %+v, %v; want 2 duplicates", result, err)
+	}
+}
+
+func TestImportCSVRowErrors(t *testing.T) {
+	s := NewMemoryStore()
+	input := strings.Join([]string{
+		"Date,Drink,ML,ABV,Cost,Currency",
+		"2024-03-05,Beer,500,,6.50,",
+		"2024-03-05,Beer,500,,6.50,",
+		"2024-02-30,Beer,500,,,",
+		"2024-03-05,,500,,,",
+		"2024-03-05,Beer,lots,,,",
+		"2024-03-05,Beer,500,,-1,",
+		"2024-03-05,Beer,500,,1,XYZ",
+		"2024-03-05,Moonshine,50,,,",
+		"2024-03-05,Moonshine,50,60,,",
+	}, "\n")
+
+	result, err := ImportCSV(s, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	if result.Imported != 2 || result.Duplicates != 1 {
+		t.Errorf("imported %d with %d duplicates, want 2 and 1", result.Imported, result.Duplicates)
+	}
+
+	rows := []int{}
+	for _, rowErr := range result.Errors {
+		rows = append(rows, rowErr.Row)
+	}
+	if want := []int{4, 5, 6, 7, 8, 9}; len(rows) != len(want) {
+		t.Errorf("errors on rows %v, want %v", rows, want)
+	} else {
+		for i := range want {
+			if rows[i] != want[i] {
+				t.Errorf("errors on rows %v, want %v", rows, want)
+				break
+			}
+		}
+	}
+
+	entries, _ := GetEntriesByDateCategory(s, 2024, 3, 5, "Beer")
+	if len(entries) != 1 || entries[0].Cost != 650 || entries[0].Currency != "USD" {
+		t.Errorf("stored %+v, want one 6.50 USD beer", entries)
+	}
+
+	if _, err := ImportCSV(s, strings.NewReader("date,drink\n")); err == nil {
+		t.Error("a file without an ml column was accepted")
+	}
+}
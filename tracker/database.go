@@ -2,7 +2,11 @@ package tracker
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -11,91 +15,278 @@ import (
 
 // Define the structure for tracking data
 type DayData struct {
-	Alcohol   string  `json:"alcohol"`
-	Quantity  int     `json:"quantity"`
-	Cost      float64 `json:"cost"`
-	Timestamp int64   `json:"timestamp"`
+	ID           string    `json:"id"`
+	Alcohol      string    `json:"alcohol"`
+	Quantity     float64   `json:"quantity"`    // Volume in mL
+	Cost         int64     `json:"cost_minor"`  // In minor units (e.g. cents) of Currency
+	Currency     string    `json:"currency"`    // ISO 4217 code
+	ConsumedAt   time.Time `json:"consumed_at"` // When the drink was had, in the zone it was logged in
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ABV          float64   `json:"abv"`           // ABV at the time the entry was written
+	AlcoholGrams float64   `json:"alcohol_grams"` // Pure alcohol in the entry, from Quantity and ABV
+
+	// Timestamp is the Unix creation time stored before CreatedAt existed; only migrations read it
+	Timestamp int64 `json:"timestamp,omitempty"`
+
+	// LegacyCost is the unitless cost stored before Cost and Currency existed; only migrations read it
+	LegacyCost float64 `json:"cost,omitempty"`
 }
 
-// Global database instance
-var db *bbolt.DB
+// DefaultDBFile is the database file name inside DataDir
+const DefaultDBFile = "tracker.db"
+
+// dbOpenTimeout is how long InitDB waits for another process to release the database
+const dbOpenTimeout = 2 * time.Second
+
+var (
+	trackerBucket  = []byte("Tracker")
+	catalogBucket  = []byte("Catalog")
+	settingsBucket = []byte("Settings")
+)
+
+// BoltStore keeps entries in a bbolt file (Hierarchical: Year → Month → Day → Category)
+type BoltStore struct {
+	db   *bbolt.DB
+	path string
+}
 
 // Initialize the BoltDB database
-func InitDB() error {
-	var err error
-	db, err = bbolt.Open("tracker.db", 0600, nil) // Creates or opens the database
+func InitDB(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	// Creates or opens the database. Only one process can hold it open, so give
+	// up rather than wait forever when another (e.g. the app or CLI) has it.
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: dbOpenTimeout})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, fmt.Errorf("%s is in use by another process", path)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Println("Database initialized")
-	return nil
+	// Bring older files up to the current schema before anything reads them
+	if _, err := Migrate(db, path, false); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Println("Database initialized")
+	return &BoltStore{db: db, path: path}, nil
 }
 
-// Add a new tracker entry (Hierarchical: Year → Month → Day → Category)
-func AddTrackerEntry(year, month, day int, category string, data DayData) error {
-	return db.Update(func(tx *bbolt.Tx) error {
-		// Create/Get top-level "Tracker" bucket
-		root, err := tx.CreateBucketIfNotExists([]byte("Tracker"))
-		if err != nil {
-			return err
+// getDayBucket walks the Tracker → Year → Month → Day chain, returning nil if any level is missing
+func getDayBucket(tx *bbolt.Tx, year, month, day int) *bbolt.Bucket {
+	monthBucket := getMonthBucket(tx, year, month)
+	if monthBucket == nil {
+		return nil
+	}
+	return monthBucket.Bucket([]byte(fmt.Sprintf("%02d", day)))
+}
+
+// getMonthBucket walks the Tracker → Year → Month chain, returning nil if any level is missing
+func getMonthBucket(tx *bbolt.Tx, year, month int) *bbolt.Bucket {
+	yearBucket := getYearBucket(tx, year)
+	if yearBucket == nil {
+		return nil
+	}
+	return yearBucket.Bucket([]byte(fmt.Sprintf("%02d", month)))
+}
+
+// getYearBucket walks the Tracker → Year chain, returning nil if any level is missing
+func getYearBucket(tx *bbolt.Tx, year int) *bbolt.Bucket {
+	root := tx.Bucket(trackerBucket)
+	if root == nil {
+		return nil
+	}
+	return root.Bucket([]byte(fmt.Sprintf("%d", year)))
+}
+
+// lookupDayBucket is getDayBucket with a descriptive error for the first missing level
+func lookupDayBucket(tx *bbolt.Tx, year, month, day int) (*bbolt.Bucket, error) {
+	root := tx.Bucket(trackerBucket)
+	if root == nil {
+		return nil, fmt.Errorf("tracker data not found")
+	}
+	if getYearBucket(tx, year) == nil {
+		return nil, fmt.Errorf("no data found for year %d", year)
+	}
+	if getMonthBucket(tx, year, month) == nil {
+		return nil, fmt.Errorf("no data found for month %02d in year %d", month, year)
+	}
+	dayBucket := getDayBucket(tx, year, month, day)
+	if dayBucket == nil {
+		return nil, fmt.Errorf("no data found for day %02d in month %02d of year %d", day, month, year)
+	}
+	return dayBucket, nil
+}
+
+// createDayBucket creates/gets every level of the Tracker → Year → Month → Day chain
+func createDayBucket(tx *bbolt.Tx, year, month, day int) (*bbolt.Bucket, error) {
+	// Create/Get top-level "Tracker" bucket
+	root, err := tx.CreateBucketIfNotExists(trackerBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create/Get Year bucket
+	yearBucket, err := root.CreateBucketIfNotExists([]byte(fmt.Sprintf("%d", year)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Create/Get Month bucket
+	monthBucket, err := yearBucket.CreateBucketIfNotExists([]byte(fmt.Sprintf("%02d", month)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Create/Get Day bucket
+	return monthBucket.CreateBucketIfNotExists([]byte(fmt.Sprintf("%02d", day)))
+}
+
+// forEachDayBucket calls fn for every Year → Month → Day bucket in the tracker
+func forEachDayBucket(tx *bbolt.Tx, fn func(year, month, day int, dayBucket *bbolt.Bucket) error) error {
+	root := tx.Bucket(trackerBucket)
+	if root == nil {
+		return nil
+	}
+
+	return root.ForEach(func(yearKey, _ []byte) error {
+		yearBucket := root.Bucket(yearKey)
+		if yearBucket == nil {
+			return nil
 		}
+		year, _ := strconv.Atoi(string(yearKey))
+
+		return yearBucket.ForEach(func(monthKey, _ []byte) error {
+			monthBucket := yearBucket.Bucket(monthKey)
+			if monthBucket == nil {
+				return nil
+			}
+			month, _ := strconv.Atoi(string(monthKey))
+
+			return monthBucket.ForEach(func(dayKey, _ []byte) error {
+				dayBucket := monthBucket.Bucket(dayKey)
+				if dayBucket == nil {
+					return nil
+				}
+				day, _ := strconv.Atoi(string(dayKey))
 
-		// Create/Get Year bucket
-		yearBucket, err := root.CreateBucketIfNotExists([]byte(fmt.Sprintf("%d", year)))
+				return fn(year, month, day, dayBucket)
+			})
+		})
+	})
+}
+
+// assignMissingIDs gives every stored entry without an ID a new one
+func assignMissingIDs(tx *bbolt.Tx) error {
+	return forEachDayBucket(tx, func(year, month, day int, dayBucket *bbolt.Bucket) error {
+		entries, err := readDay(dayBucket)
 		if err != nil {
 			return err
 		}
 
-		// Create/Get Month bucket
-		monthBucket, err := yearBucket.CreateBucketIfNotExists([]byte(fmt.Sprintf("%02d", month)))
-		if err != nil {
+		for category, categoryEntries := range entries {
+			changed := false
+			for i := range categoryEntries {
+				if categoryEntries[i].ID == "" {
+					categoryEntries[i].ID = newIDAt(time.Unix(categoryEntries[i].Timestamp, 0))
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+			if err := writeCategory(dayBucket, category, categoryEntries); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// readCategory decodes the JSON array stored under a category key
+func readCategory(dayBucket *bbolt.Bucket, category string) ([]DayData, error) {
+	var entries []DayData
+	value := dayBucket.Get([]byte(category))
+	if value == nil {
+		return entries, nil
+	}
+	if err := json.Unmarshal(value, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// writeCategory stores entries under a category key, deleting the key when none remain
+func writeCategory(dayBucket *bbolt.Bucket, category string, entries []DayData) error {
+	entryKey := []byte(category)
+	if len(entries) == 0 {
+		return dayBucket.Delete(entryKey)
+	}
+
+	newData, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return dayBucket.Put(entryKey, newData)
+}
+
+// readDay decodes every category stored in a day bucket
+func readDay(dayBucket *bbolt.Bucket) (map[string][]DayData, error) {
+	entries := make(map[string][]DayData) // Structure: Category → Entries
+
+	err := dayBucket.ForEach(func(categoryKey, value []byte) error {
+		var dayEntries []DayData
+		if err := json.Unmarshal(value, &dayEntries); err != nil {
 			return err
 		}
 
-		// Create/Get Day bucket
-		dayBucket, err := monthBucket.CreateBucketIfNotExists([]byte(fmt.Sprintf("%02d", day)))
+		entries[string(categoryKey)] = dayEntries
+		return nil
+	})
+
+	return entries, err
+}
+
+// Add a new tracker entry (Hierarchical: Year → Month → Day → Category)
+func (s *BoltStore) AddEntry(year, month, day int, category string, data DayData) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		dayBucket, err := createDayBucket(tx, year, month, day)
 		if err != nil {
 			return err
 		}
 
-		// Convert entry to JSON
-		entryKey := []byte(category)
-		var entries []DayData
-
 		// Check if an entry already exists for this category on this day
-		existingData := dayBucket.Get(entryKey)
-		if existingData != nil {
-			err = json.Unmarshal(existingData, &entries)
-			if err != nil {
-				return err
-			}
+		entries, err := readCategory(dayBucket, category)
+		if err != nil {
+			return err
 		}
 
 		// Append the new data entry
+		if data.ID == "" {
+			data.ID = NewID()
+		}
 		entries = append(entries, data)
 
-		// Store updated data
-		newData, err := json.Marshal(entries)
-		if err != nil {
-			return err
-		}
-		fmt.Printf("Entry Added for /%d/%02d/%02d/%s \n ", year, month, day, category)
-		return dayBucket.Put(entryKey, newData)
+		log.Printf("Entry Added for /%d/%02d/%02d/%s", year, month, day, category)
+		return writeCategory(dayBucket, category, entries)
 	})
 }
 
-// Get all entries for a given year (structured as Year → Month → Day)
-func GetEntriesByYear(year int) (map[string]map[string]map[string][]DayData, error) {
-	entries := make(map[string]map[string]map[string][]DayData) // Structure: Year -> Month -> Day -> Entries
+// Get all entries for a given year (structured as Month → Day → Category)
+func (s *BoltStore) GetEntriesByYear(year int) (map[string]map[string]map[string][]DayData, error) {
+	entries := make(map[string]map[string]map[string][]DayData) // Structure: Month -> Day -> Entries
 
-	err := db.View(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
-		if root == nil {
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if tx.Bucket(trackerBucket) == nil {
 			return fmt.Errorf("tracker data not found")
 		}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", year)))
+		yearBucket := getYearBucket(tx, year)
 		if yearBucket == nil {
 			return fmt.Errorf("no data found for year %d", year)
 		}
@@ -117,20 +308,13 @@ func GetEntriesByYear(year int) (map[string]map[string]map[string][]DayData, err
 					return nil
 				}
 
-				dayStr := string(dayKey)
-				entries[monthStr][dayStr] = make(map[string][]DayData)
-
-				// Iterate over categories
-				return dayBucket.ForEach(func(categoryKey, value []byte) error {
-					var dayEntries []DayData
-					if err := json.Unmarshal(value, &dayEntries); err != nil {
-						return err
-					}
+				dayEntries, err := readDay(dayBucket)
+				if err != nil {
+					return err
+				}
 
-					categoryStr := string(categoryKey)
-					entries[monthStr][dayStr][categoryStr] = dayEntries
-					return nil
-				})
+				entries[monthStr][string(dayKey)] = dayEntries
+				return nil
 			})
 		})
 	})
@@ -138,22 +322,20 @@ func GetEntriesByYear(year int) (map[string]map[string]map[string][]DayData, err
 	return entries, err
 }
 
-// Get all entries for a given year & month (structured as Month → Day)
-func GetEntriesByYearAndMonth(year, month int) (map[string]map[string][]DayData, error) {
-	entries := make(map[string]map[string][]DayData) // Structure: Month → Day → Entries
+// Get all entries for a given year & month (structured as Day → Category)
+func (s *BoltStore) GetEntriesByYearAndMonth(year, month int) (map[string]map[string][]DayData, error) {
+	entries := make(map[string]map[string][]DayData) // Structure: Day → Entries
 
-	err := db.View(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
-		if root == nil {
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if tx.Bucket(trackerBucket) == nil {
 			return fmt.Errorf("tracker data not found")
 		}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", year)))
-		if yearBucket == nil {
+		if getYearBucket(tx, year) == nil {
 			return fmt.Errorf("no data found for year %d", year)
 		}
 
-		monthBucket := yearBucket.Bucket([]byte(fmt.Sprintf("%02d", month)))
+		monthBucket := getMonthBucket(tx, year, month)
 		if monthBucket == nil {
 			return fmt.Errorf("no data found for month %02d in year %d", month, year)
 		}
@@ -165,20 +347,13 @@ func GetEntriesByYearAndMonth(year, month int) (map[string]map[string][]DayData,
 				return nil
 			}
 
-			dayStr := string(dayKey)
-			entries[dayStr] = make(map[string][]DayData)
-
-			// Iterate over categories
-			return dayBucket.ForEach(func(categoryKey, value []byte) error {
-				var dayEntries []DayData
-				if err := json.Unmarshal(value, &dayEntries); err != nil {
-					return err
-				}
+			dayEntries, err := readDay(dayBucket)
+			if err != nil {
+				return err
+			}
 
-				categoryStr := string(categoryKey)
-				entries[dayStr][categoryStr] = dayEntries
-				return nil
-			})
+			entries[string(dayKey)] = dayEntries
+			return nil
 		})
 	})
 
@@ -186,224 +361,469 @@ func GetEntriesByYearAndMonth(year, month int) (map[string]map[string][]DayData,
 }
 
 // Get all entries for a specific year, month, and day
-func GetEntriesByDate(year, month, day int) (map[string][]DayData, error) {
-	entries := make(map[string][]DayData) // Structure: Category → Entries
+func (s *BoltStore) GetEntriesByDate(year, month, day int) (map[string][]DayData, error) {
+	var entries map[string][]DayData
 
-	err := db.View(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
-		if root == nil {
-			return fmt.Errorf("tracker data not found")
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		dayBucket, err := lookupDayBucket(tx, year, month, day)
+		if err != nil {
+			return err
 		}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", year)))
-		if yearBucket == nil {
-			return fmt.Errorf("no data found for year %d", year)
-		}
+		entries, err = readDay(dayBucket)
+		return err
+	})
 
-		monthBucket := yearBucket.Bucket([]byte(fmt.Sprintf("%02d", month)))
-		if monthBucket == nil {
-			return fmt.Errorf("no data found for month %02d in year %d", month, year)
-		}
+	return entries, err
+}
+
+// GetRecordsInRange walks the Year → Month → Day buckets with cursors, seeking
+// straight to the start date and stopping after the end date
+func (s *BoltStore) GetRecordsInRange(from, to Date) ([]Record, error) {
+	records := []Record{}
+	fromKey, toKey := from.String(), to.String()
 
-		dayBucket := monthBucket.Bucket([]byte(fmt.Sprintf("%02d", day)))
-		if dayBucket == nil {
-			return fmt.Errorf("no data found for day %02d in month %02d of year %d", day, month, year)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		root := tx.Bucket(trackerBucket)
+		if root == nil {
+			return nil
 		}
 
-		// Iterate over categories
-		return dayBucket.ForEach(func(categoryKey, value []byte) error {
-			var dayEntries []DayData
-			if err := json.Unmarshal(value, &dayEntries); err != nil {
-				return err
+		years := root.Cursor()
+		for yearKey, _ := years.Seek([]byte(fmt.Sprintf("%d", from.Year))); yearKey != nil; yearKey, _ = years.Next() {
+			if string(yearKey) > toKey[:4] {
+				break
+			}
+			yearBucket := root.Bucket(yearKey)
+			if yearBucket == nil {
+				continue
 			}
 
-			categoryStr := string(categoryKey)
-			entries[categoryStr] = dayEntries
-			return nil
-		})
+			months := yearBucket.Cursor()
+			for monthKey, _ := months.First(); monthKey != nil; monthKey, _ = months.Next() {
+				yearMonth := fmt.Sprintf("%s-%s", yearKey, monthKey)
+				if yearMonth < fromKey[:7] {
+					continue
+				}
+				if yearMonth > toKey[:7] {
+					break
+				}
+
+				monthBucket := yearBucket.Bucket(monthKey)
+				if monthBucket == nil {
+					continue
+				}
+
+				days := monthBucket.Cursor()
+				for dayKey, _ := days.First(); dayKey != nil; dayKey, _ = days.Next() {
+					key := fmt.Sprintf("%s-%s-%s", yearKey, monthKey, dayKey)
+					if key < fromKey {
+						continue
+					}
+					if key > toKey {
+						return nil
+					}
+
+					dayBucket := monthBucket.Bucket(dayKey)
+					if dayBucket == nil {
+						continue
+					}
+
+					entries, err := readDay(dayBucket)
+					if err != nil {
+						return err
+					}
+
+					var date Date
+					date.Year, _ = strconv.Atoi(string(yearKey))
+					date.Month, _ = strconv.Atoi(string(monthKey))
+					date.Day, _ = strconv.Atoi(string(dayKey))
+					for _, category := range sortedKeys(entries) {
+						for _, entry := range entries[category] {
+							records = append(records, Record{Date: date, Category: category, Entry: entry})
+						}
+					}
+				}
+			}
+		}
+		return nil
 	})
 
-	return entries, err
+	return records, err
 }
 
-// Get all entries for a specific year, month, and day as a flat list
-func GetEntriesByDateList(year, month, day int) ([]DayData, error) {
-	allEntries := []DayData{} // Always initialized as an empty slice
+// FindLatestEntryDate retrieves the latest (most recent) entry from the database.
+func (s *BoltStore) FindLatestEntryDate() (int, int, int, error) {
+	var latestYear, latestMonth, latestDay int
 
-	err := db.View(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		root := tx.Bucket(trackerBucket)
 		if root == nil {
-			return nil // No data found, return empty list
+			return fmt.Errorf("tracker data not found")
+		}
+
+		// Iterate over years to find the most recent year
+		err := root.ForEach(func(yearKey, _ []byte) error {
+			year, _ := strconv.Atoi(string(yearKey))
+			if year > latestYear {
+				latestYear = year
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if latestYear == 0 {
+			return fmt.Errorf("no data found in database")
 		}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", year)))
+		yearBucket := getYearBucket(tx, latestYear)
 		if yearBucket == nil {
-			return nil // No data found, return empty list
+			return fmt.Errorf("no data found for latest year %d", latestYear)
 		}
 
-		monthBucket := yearBucket.Bucket([]byte(fmt.Sprintf("%02d", month)))
-		if monthBucket == nil {
-			return nil // No data found, return empty list
+		// Iterate over months to find the most recent month
+		err = yearBucket.ForEach(func(monthKey, _ []byte) error {
+			month, _ := strconv.Atoi(string(monthKey))
+			if month > latestMonth {
+				latestMonth = month
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
-		dayBucket := monthBucket.Bucket([]byte(fmt.Sprintf("%02d", day)))
-		if dayBucket == nil {
-			return nil // No data found, return empty list
+		monthBucket := getMonthBucket(tx, latestYear, latestMonth)
+		if monthBucket == nil {
+			return fmt.Errorf("no data found for latest month %02d in year %d", latestMonth, latestYear)
 		}
 
-		// Iterate over all categories and collect data
-		_ = dayBucket.ForEach(func(categoryKey, value []byte) error {
-			var entries []DayData
-			if err := json.Unmarshal(value, &entries); err != nil {
-				fmt.Printf("Warning: Failed to parse category %s: %v\n", string(categoryKey), err)
-				return nil // Continue processing other categories even if one fails
+		// Iterate over days to find the most recent day
+		return monthBucket.ForEach(func(dayKey, _ []byte) error {
+			day, _ := strconv.Atoi(string(dayKey))
+			if day > latestDay {
+				latestDay = day
 			}
-
-			// Append all category entries to the final list
-			allEntries = append(allEntries, entries...)
 			return nil
 		})
-
-		return nil // Always return nil to continue iteration
 	})
 
-	// Ensure we return an empty slice, never nil
 	if err != nil {
-		return []DayData{}, err
+		return 0, 0, 0, err
 	}
 
-	return allEntries, nil
+	return latestYear, latestMonth, latestDay, nil
 }
 
-// Get all entries for a specific year, month, day, and category
-func GetEntriesByDateCategory(year, month, day int, category string) ([]DayData, error) {
-	var entries []DayData
+// findEntry locates the category and index of the entry with the given ID in a day bucket
+func findEntry(dayBucket *bbolt.Bucket, id string) (string, []DayData, int, error) {
+	entries, err := readDay(dayBucket)
+	if err != nil {
+		return "", nil, -1, err
+	}
 
-	err := db.View(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
-		if root == nil {
-			return fmt.Errorf("tracker data not found")
+	for category, categoryEntries := range entries {
+		for i, entry := range categoryEntries {
+			if entry.ID == id {
+				return category, categoryEntries, i, nil
+			}
 		}
+	}
+	return "", nil, -1, NotFoundError{Kind: "entry", ID: id}
+}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", year)))
-		if yearBucket == nil {
-			return fmt.Errorf("no data found for year %d", year)
+// GetEntry returns the entry with the given ID on a day
+func (s *BoltStore) GetEntry(year, month, day int, id string) (DayData, error) {
+	var entry DayData
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		dayBucket, err := lookupDayBucket(tx, year, month, day)
+		if err != nil {
+			return err
 		}
 
-		monthBucket := yearBucket.Bucket([]byte(fmt.Sprintf("%02d", month)))
-		if monthBucket == nil {
-			return fmt.Errorf("no data found for month %02d in year %d", month, year)
+		_, entries, i, err := findEntry(dayBucket, id)
+		if err != nil {
+			return err
+		}
+		entry = entries[i]
+		return nil
+	})
+
+	return entry, err
+}
+
+// UpdateEntry replaces the entry with the matching ID in a single transaction,
+// moving it to another date or category (data.Alcohol) when those differ.
+func (s *BoltStore) UpdateEntry(year, month, day int, id string, newYear, newMonth, newDay int, data DayData) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		dayBucket, err := lookupDayBucket(tx, year, month, day)
+		if err != nil {
+			return err
+		}
+
+		category, entries, i, err := findEntry(dayBucket, id)
+		if err != nil {
+			return err
 		}
 
-		dayBucket := monthBucket.Bucket([]byte(fmt.Sprintf("%02d", day)))
-		if dayBucket == nil {
-			return fmt.Errorf("no data found for day %02d in month %02d of year %d", day, month, year)
+		data.ID = id
+		if data.Alcohol == "" {
+			data.Alcohol = category
 		}
 
-		// Retrieve the specific category data
-		value := dayBucket.Get([]byte(category))
-		if value == nil {
-			return fmt.Errorf("no data found for category '%s' on %02d-%02d-%d", category, day, month, year)
+		// Same day and category: edit in place, keeping the entry's position
+		sameDay := year == newYear && month == newMonth && day == newDay
+		if sameDay && data.Alcohol == category {
+			entries[i] = data
+			return writeCategory(dayBucket, category, entries)
 		}
 
-		// Unmarshal JSON into entries slice
-		if err := json.Unmarshal(value, &entries); err != nil {
+		// Otherwise remove it from the old key and append it under the new one
+		if err := writeCategory(dayBucket, category, append(entries[:i], entries[i+1:]...)); err != nil {
+			return err
+		}
+		if err := pruneDayBucket(tx, year, month, day); err != nil {
 			return err
 		}
 
-		return nil
+		newDayBucket, err := createDayBucket(tx, newYear, newMonth, newDay)
+		if err != nil {
+			return err
+		}
+
+		newEntries, err := readCategory(newDayBucket, data.Alcohol)
+		if err != nil {
+			return err
+		}
+		return writeCategory(newDayBucket, data.Alcohol, append(newEntries, data))
 	})
+}
 
-	return entries, err
+// DeleteEntry removes the entry with the given ID from a day.
+func (s *BoltStore) DeleteEntry(year, month, day int, id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		dayBucket, err := lookupDayBucket(tx, year, month, day)
+		if err != nil {
+			return err
+		}
+
+		category, entries, i, err := findEntry(dayBucket, id)
+		if err != nil {
+			return err
+		}
+
+		// If no entries remain, the category key is deleted
+		if err := writeCategory(dayBucket, category, append(entries[:i], entries[i+1:]...)); err != nil {
+			return err
+		}
+		return pruneDayBucket(tx, year, month, day)
+	})
 }
 
-// PrintDaysInYear prints all the days present in the database for a given year
-// PrintDaysWithDataInYear prints all days with their associated DayData entries for a given year
-func PrintDaysInYear(year int) error {
-	return db.View(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
-		if root == nil {
-			fmt.Println("No tracker data found.")
+// pruneDayBucket deletes a day bucket left without entries, then its month and year
+// buckets if they are left empty too, so every stored day is a day with drinks
+func pruneDayBucket(tx *bbolt.Tx, year, month, day int) error {
+	dayKey := []byte(fmt.Sprintf("%02d", day))
+	monthKey := []byte(fmt.Sprintf("%02d", month))
+	yearKey := []byte(fmt.Sprintf("%d", year))
+
+	monthBucket := getMonthBucket(tx, year, month)
+	if monthBucket == nil || !isEmptyBucket(monthBucket.Bucket(dayKey)) {
+		return nil
+	}
+	if err := monthBucket.DeleteBucket(dayKey); err != nil {
+		return err
+	}
+
+	yearBucket := getYearBucket(tx, year)
+	if !isEmptyBucket(yearBucket.Bucket(monthKey)) {
+		return nil
+	}
+	if err := yearBucket.DeleteBucket(monthKey); err != nil {
+		return err
+	}
+
+	if !isEmptyBucket(yearBucket) {
+		return nil
+	}
+	return tx.Bucket(trackerBucket).DeleteBucket(yearKey)
+}
+
+// isEmptyBucket reports whether a bucket exists and holds no keys
+func isEmptyBucket(b *bbolt.Bucket) bool {
+	if b == nil {
+		return false
+	}
+	key, _ := b.Cursor().First()
+	return key == nil
+}
+
+// GetDrinks returns every drink in the catalog bucket
+func (s *BoltStore) GetDrinks() ([]Drink, error) {
+	drinks := []Drink{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		catalog := tx.Bucket(catalogBucket)
+		if catalog == nil {
 			return nil
 		}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", year)))
-		if yearBucket == nil {
-			fmt.Printf("No data found for year %d\n", year)
+		return catalog.ForEach(func(_, value []byte) error {
+			var drink Drink
+			if err := json.Unmarshal(value, &drink); err != nil {
+				return err
+			}
+			drinks = append(drinks, drink)
 			return nil
+		})
+	})
+
+	return drinks, err
+}
+
+// SaveDrink stores a drink in the catalog bucket, keyed by name
+func (s *BoltStore) SaveDrink(drink Drink) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putDrink(tx, drink)
+	})
+}
+
+func putDrink(tx *bbolt.Tx, drink Drink) error {
+	catalog, err := tx.CreateBucketIfNotExists(catalogBucket)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(drink)
+	if err != nil {
+		return err
+	}
+	return catalog.Put([]byte(drink.Name), data)
+}
+
+// seedCatalog fills an empty catalog with the default drinks
+func seedCatalog(tx *bbolt.Tx) error {
+	if tx.Bucket(catalogBucket) != nil {
+		return nil
+	}
+
+	for _, drink := range defaultDrinks {
+		if err := putDrink(tx, drink); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// Iterate over months
-		return yearBucket.ForEach(func(monthKey, _ []byte) error {
-			monthBucket := yearBucket.Bucket(monthKey)
-			if monthBucket == nil {
-				return nil
-			}
+// GetSetting reads a value from the settings bucket
+func (s *BoltStore) GetSetting(key string) ([]byte, error) {
+	var value []byte
 
-			// Iterate over days
-			return monthBucket.ForEach(func(dayKey, _ []byte) error {
-				dayBucket := monthBucket.Bucket(dayKey)
-				if dayBucket == nil {
-					return nil
-				}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		settings := tx.Bucket(settingsBucket)
+		if settings == nil {
+			return nil
+		}
 
-				fmt.Printf("Entries for date: %d-%s-%s\n", year, string(monthKey), string(dayKey))
+		// Values are only valid for the life of the transaction
+		if v := settings.Get([]byte(key)); v != nil {
+			value = append([]byte(nil), v...)
+		}
+		return nil
+	})
 
-				// Iterate over categories and print DayData entries
-				return dayBucket.ForEach(func(categoryKey, value []byte) error {
-					var entries []DayData
-					if err := json.Unmarshal(value, &entries); err != nil {
-						fmt.Printf("Error parsing data for category %s: %v\n", string(categoryKey), err)
-						return nil // Continue processing other categories even if one fails
-					}
+	return value, err
+}
 
-					fmt.Printf("  Category: %s\n", string(categoryKey))
-					for _, entry := range entries {
-						fmt.Printf("    Alcohol: %s, Quantity: %d, Cost: %.2f, Timestamp: %d\n",
-							entry.Alcohol, entry.Quantity, entry.Cost, entry.Timestamp)
-					}
-					return nil
-				})
-			})
-		})
+// PutSetting writes a value to the settings bucket
+func (s *BoltStore) PutSetting(key string, value []byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		settings, err := tx.CreateBucketIfNotExists(settingsBucket)
+		if err != nil {
+			return err
+		}
+		return settings.Put([]byte(key), value)
 	})
 }
 
-// FindLatestEntryDate retrieves the latest (most recent) entry from the database.
-func FindLatestEntryDate() (int, int, int, error) {
-	var latestYear, latestMonth, latestDay int
+// UpdateSetting reads, changes and writes a settings value in one transaction
+func (s *BoltStore) UpdateSetting(key string, update func(value []byte) ([]byte, error)) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		settings, err := tx.CreateBucketIfNotExists(settingsBucket)
+		if err != nil {
+			return err
+		}
 
-	err := db.View(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
-		if root == nil {
-			return fmt.Errorf("tracker data not found")
+		// update must not touch the store, so it only sees a copy of the value
+		var current []byte
+		if v := settings.Get([]byte(key)); v != nil {
+			current = append([]byte(nil), v...)
 		}
 
-		// Iterate over years in reverse order to find the most recent year
-		err := root.ForEach(func(yearKey, _ []byte) error {
-			year, _ := strconv.Atoi(string(yearKey))
-			if year > latestYear {
-				latestYear = year
-			}
-			return nil
-		})
+		value, err := update(current)
 		if err != nil {
 			return err
 		}
-		if latestYear == 0 {
-			return fmt.Errorf("no data found in database")
+		return settings.Put([]byte(key), value)
+	})
+}
+
+// GetSettings reads every value in the settings bucket
+func (s *BoltStore) GetSettings() (map[string][]byte, error) {
+	settings := make(map[string][]byte)
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(settingsBucket)
+		if bucket == nil {
+			return nil
 		}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", latestYear)))
-		if yearBucket == nil {
-			return fmt.Errorf("no data found for latest year %d", latestYear)
+		// Values are only valid for the life of the transaction
+		return bucket.ForEach(func(key, value []byte) error {
+			settings[string(key)] = append([]byte(nil), value...)
+			return nil
+		})
+	})
+
+	return settings, err
+}
+
+// Restore writes a backup in a single transaction, so a failed restore leaves
+// the database as it was
+func (s *BoltStore) Restore(backup Backup, replace bool) (RestoreResult, error) {
+	var result RestoreResult
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		result = RestoreResult{}
+		local := map[string][]byte{}
+		if replace {
+			if bucket := tx.Bucket(settingsBucket); bucket != nil {
+				for key := range localSettings {
+					if value := bucket.Get([]byte(key)); value != nil {
+						local[key] = append([]byte(nil), value...)
+					}
+				}
+			}
+			for _, name := range [][]byte{trackerBucket, catalogBucket, settingsBucket} {
+				if err := tx.DeleteBucket(name); err != nil && err != bbolt.ErrBucketNotFound {
+					return err
+				}
+			}
 		}
 
-		// Iterate over months in reverse order to find the most recent month
-		err = yearBucket.ForEach(func(monthKey, _ []byte) error {
-			month, _ := strconv.Atoi(string(monthKey))
-			if month > latestMonth {
-				latestMonth = month
+		// An entry may have been moved to another date since the backup was made
+		stored := map[string]bool{}
+		err := forEachDayBucket(tx, func(_, _, _ int, dayBucket *bbolt.Bucket) error {
+			entries, err := readDay(dayBucket)
+			if err != nil {
+				return err
+			}
+			for _, categoryEntries := range entries {
+				for _, entry := range categoryEntries {
+					stored[entry.ID] = true
+				}
 			}
 			return nil
 		})
@@ -411,109 +831,288 @@ func FindLatestEntryDate() (int, int, int, error) {
 			return err
 		}
 
-		monthBucket := yearBucket.Bucket([]byte(fmt.Sprintf("%02d", latestMonth)))
-		if monthBucket == nil {
-			return fmt.Errorf("no data found for latest month %02d in year %d", latestMonth, latestYear)
-		}
+		for _, record := range backup.Entries {
+			if stored[record.Entry.ID] {
+				result.SkippedEntries++
+				continue
+			}
+			stored[record.Entry.ID] = true
 
-		// Iterate over days in reverse order to find the most recent day
-		err = monthBucket.ForEach(func(dayKey, _ []byte) error {
-			day, _ := strconv.Atoi(string(dayKey))
-			if day > latestDay {
-				latestDay = day
+			date := record.Date
+			dayBucket, err := createDayBucket(tx, date.Year, date.Month, date.Day)
+			if err != nil {
+				return err
 			}
-			return nil
-		})
+			entries, err := readCategory(dayBucket, record.Category)
+			if err != nil {
+				return err
+			}
+			if err := writeCategory(dayBucket, record.Category, append(entries, record.Entry)); err != nil {
+				return err
+			}
+			result.Entries++
+		}
+
+		catalog, err := tx.CreateBucketIfNotExists(catalogBucket)
 		if err != nil {
 			return err
 		}
+		for _, drink := range backup.Catalog {
+			if catalog.Get([]byte(drink.Name)) != nil {
+				result.SkippedDrinks++
+				continue
+			}
+			if err := putDrink(tx, drink); err != nil {
+				return err
+			}
+			result.Drinks++
+		}
 
+		settings, err := tx.CreateBucketIfNotExists(settingsBucket)
+		if err != nil {
+			return err
+		}
+		for key, value := range local {
+			if err := settings.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		for key, value := range backup.Settings {
+			if current := settings.Get([]byte(key)); current != nil {
+				merged, changed, err := mergeSetting(key, current, value)
+				if err != nil {
+					return fmt.Errorf("failed to merge setting %s: %v", key, err)
+				}
+				if !changed {
+					result.SkippedSettings++
+					continue
+				}
+				value = merged
+			}
+			if err := settings.Put([]byte(key), value); err != nil {
+				return err
+			}
+			result.Settings++
+		}
 		return nil
 	})
 
-	if err != nil {
-		return 0, 0, 0, err
-	}
-
-	return latestYear, latestMonth, latestDay, nil
+	return result, err
 }
 
-// GetDaysSinceLastEntry calculates the number of days since the latest entry.
-func GetDaysSinceLastEntry() (int, error) {
-	latestYear, latestMonth, latestDay, err := FindLatestEntryDate()
-	if err != nil {
-		return -1, err
+// backfillAlcohol stamps ABV and alcohol grams on entries written before they were stored,
+// using the catalog as it is at migration time
+func backfillAlcohol(tx *bbolt.Tx) error {
+	catalog := make(Catalog)
+	if bucket := tx.Bucket(catalogBucket); bucket != nil {
+		err := bucket.ForEach(func(_, value []byte) error {
+			var drink Drink
+			if err := json.Unmarshal(value, &drink); err != nil {
+				return err
+			}
+			catalog[drink.Name] = drink
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	latestDate := time.Date(latestYear, time.Month(latestMonth), latestDay, 0, 0, 0, 0, time.UTC)
-	today := time.Now().UTC()
-	daysSince := int(today.Sub(latestDate).Hours() / 24)
+	return forEachDayBucket(tx, func(year, month, day int, dayBucket *bbolt.Bucket) error {
+		entries, err := readDay(dayBucket)
+		if err != nil {
+			return err
+		}
 
-	return daysSince, nil
+		for category, categoryEntries := range entries {
+			for i := range categoryEntries {
+				if categoryEntries[i].ABV == 0 && categoryEntries[i].AlcoholGrams == 0 {
+					// Stored history can't be rejected, so unknown drinks get a spirit's ABV
+					abv, err := catalog.ABV(category)
+					if err != nil {
+						abv = fallbackABV
+					}
+					categoryEntries[i].ABV = abv
+					categoryEntries[i].AlcoholGrams = AlcoholGrams(categoryEntries[i].Quantity, categoryEntries[i].ABV)
+				}
+			}
+			if err := writeCategory(dayBucket, category, categoryEntries); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// DeleteEntry removes an entry based on the given year, month, day, category, and timestamp.
-func DeleteEntry(year, month, day int, category string, timestamp int64) error {
-	return db.Update(func(tx *bbolt.Tx) error {
-		root := tx.Bucket([]byte("Tracker"))
-		if root == nil {
-			return fmt.Errorf("tracker data not found")
+// backfillTimes fills ConsumedAt, CreatedAt and UpdatedAt from the legacy Timestamp.
+// The timestamp is only a good consumption time when it falls on the entry's date;
+// otherwise the drink was backfilled and is placed at noon on that date.
+func backfillTimes(tx *bbolt.Tx) error {
+	return forEachDayBucket(tx, func(year, month, day int, dayBucket *bbolt.Bucket) error {
+		entries, err := readDay(dayBucket)
+		if err != nil {
+			return err
 		}
 
-		yearBucket := root.Bucket([]byte(fmt.Sprintf("%d", year)))
-		if yearBucket == nil {
-			return fmt.Errorf("no data found for year %d", year)
+		for category, categoryEntries := range entries {
+			for i := range categoryEntries {
+				entry := &categoryEntries[i]
+				if !entry.ConsumedAt.IsZero() {
+					continue
+				}
+
+				created := time.Unix(entry.Timestamp, 0)
+				entry.CreatedAt = created
+				entry.UpdatedAt = created
+				if created.Year() == year && int(created.Month()) == month && created.Day() == day {
+					entry.ConsumedAt = created
+				} else {
+					entry.ConsumedAt = time.Date(year, time.Month(month), day, 12, 0, 0, 0, time.Local)
+				}
+				entry.Timestamp = 0
+			}
+			if err := writeCategory(dayBucket, category, categoryEntries); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
 
-		monthBucket := yearBucket.Bucket([]byte(fmt.Sprintf("%02d", month)))
-		if monthBucket == nil {
-			return fmt.Errorf("no data found for month %02d in year %d", month, year)
+// convertCosts moves entry costs to minor units of a currency. Costs were entered
+// under a "$" label, so they are taken to be US dollars. Money settings written
+// before this change are converted the same way.
+func convertCosts(tx *bbolt.Tx) error {
+	err := forEachDayBucket(tx, func(year, month, day int, dayBucket *bbolt.Bucket) error {
+		entries, err := readDay(dayBucket)
+		if err != nil {
+			return err
 		}
 
-		dayBucket := monthBucket.Bucket([]byte(fmt.Sprintf("%02d", day)))
-		if dayBucket == nil {
-			return fmt.Errorf("no data found for day %02d in month %02d of year %d", day, month, year)
+		for category, categoryEntries := range entries {
+			for i := range categoryEntries {
+				entry := &categoryEntries[i]
+				if entry.Currency != "" {
+					continue
+				}
+				entry.Cost = USDollar.ToMinor(entry.LegacyCost)
+				entry.Currency = USDollar.Code
+				entry.LegacyCost = 0
+			}
+			if err := writeCategory(dayBucket, category, categoryEntries); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	settings := tx.Bucket(settingsBucket)
+	if settings == nil {
+		return nil
+	}
 
-		// Retrieve the specific category data
-		entryKey := []byte(category)
-		value := dayBucket.Get(entryKey)
-		if value == nil {
-			return fmt.Errorf("no data found for category '%s' on %02d-%02d-%d", category, day, month, year)
+	// The monthly budget was a bare number
+	if value := settings.Get([]byte(monthlyBudgetKey)); value != nil {
+		var budget float64
+		if err := json.Unmarshal(value, &budget); err == nil {
+			converted, err := json.Marshal(Money{Amount: USDollar.ToMinor(budget), Currency: USDollar.Code})
+			if err != nil {
+				return err
+			}
+			if err := settings.Put([]byte(monthlyBudgetKey), converted); err != nil {
+				return err
+			}
 		}
+	}
 
-		// Unmarshal JSON into entries slice
-		var entries []DayData
-		if err := json.Unmarshal(value, &entries); err != nil {
+	// Spending goal targets were in dollars
+	if value := settings.Get([]byte(goalsKey)); value != nil {
+		goals := []Goal{}
+		if err := json.Unmarshal(value, &goals); err != nil {
 			return err
 		}
-
-		// Filter out the entry with the matching timestamp
-		filteredEntries := []DayData{}
-		for _, entry := range entries {
-			if entry.Timestamp != timestamp {
-				filteredEntries = append(filteredEntries, entry)
+		for i := range goals {
+			if goals[i].Kind == GoalMaxSpend {
+				goals[i].Target = float64(USDollar.ToMinor(goals[i].Target))
 			}
 		}
+		converted, err := json.Marshal(goals)
+		if err != nil {
+			return err
+		}
+		if err := settings.Put([]byte(goalsKey), converted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		// If no entries remain, delete the category key
-		if len(filteredEntries) == 0 {
-			return dayBucket.Delete(entryKey)
+// pruneEmptyDays removes the day buckets that deleting an entry used to leave behind
+func pruneEmptyDays(tx *bbolt.Tx) error {
+	empty := []Date{}
+	err := forEachDayBucket(tx, func(year, month, day int, dayBucket *bbolt.Bucket) error {
+		if isEmptyBucket(dayBucket) {
+			empty = append(empty, Date{Year: year, Month: month, Day: day})
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		// Store updated data
-		newData, err := json.Marshal(filteredEntries)
-		if err != nil {
+	// Buckets can't be deleted while they are being iterated
+	for _, date := range empty {
+		if err := pruneDayBucket(tx, date.Year, date.Month, date.Day); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		return dayBucket.Put(entryKey, newData)
-	})
+// stampGoalCurrencies records the currency of spending goal targets, which were
+// in the reporting currency of the time
+func stampGoalCurrencies(tx *bbolt.Tx) error {
+	settings := tx.Bucket(settingsBucket)
+	if settings == nil {
+		return nil
+	}
+	value := settings.Get([]byte(goalsKey))
+	if value == nil {
+		return nil
+	}
+
+	// Resolved as GetReportingCurrency does: the reporting currency, else the default one
+	code := USDollar.Code
+	for _, key := range []string{defaultCurrencyKey, reportingCurrencyKey} {
+		setting := ""
+		if raw := settings.Get([]byte(key)); raw != nil && json.Unmarshal(raw, &setting) == nil && setting != "" {
+			code = setting
+		}
+	}
+
+	goals := []Goal{}
+	if err := json.Unmarshal(value, &goals); err != nil {
+		return err
+	}
+	for i := range goals {
+		if goals[i].Kind == GoalMaxSpend && goals[i].Currency == "" {
+			goals[i].Currency = code
+		}
+	}
+	stamped, err := json.Marshal(goals)
+	if err != nil {
+		return err
+	}
+	return settings.Put([]byte(goalsKey), stamped)
 }
 
 // Close the database connection
-func CloseDB() {
-	if db != nil {
-		db.Close()
+func (s *BoltStore) Close() error {
+	if s.db != nil {
+		return s.db.Close()
 	}
+	return nil
 }
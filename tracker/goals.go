@@ -0,0 +1,365 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Goal kinds
+const (
+	GoalMaxDrinks          = "max_drinks"            // At most Target standard drinks per period
+	GoalMinAlcoholFreeDays = "min_alcohol_free_days" // At least Target alcohol-free days per period
+	GoalMaxSpend           = "max_spend"             // Spend less than Target, in minor units of Currency, per period
+)
+
+// Goal is a limit checked over every week, month or year
+type Goal struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Period    string    `json:"period"` // PeriodWeek, PeriodMonth or PeriodYear
+	Target    float64   `json:"target"`
+	Currency  string    `json:"currency,omitempty"` // Of a spending goal's Target; empty for other kinds
+	CreatedAt time.Time `json:"created_at"`         // Periods are checked from the one holding this time
+}
+
+// GoalResult is the outcome of a goal over a finished period
+type GoalResult struct {
+	GoalID   string  `json:"goal_id"`
+	From     Date    `json:"from"`
+	To       Date    `json:"to"`
+	Actual   float64 `json:"actual"`
+	Target   float64 `json:"target"`
+	Currency string  `json:"currency,omitempty"` // Of a spending goal's Actual and Target, the reporting currency
+	Passed   bool    `json:"passed"`
+}
+
+// GoalProgress is how a goal stands in the period in progress
+type GoalProgress struct {
+	From     Date    `json:"from"`
+	To       Date    `json:"to"`
+	Actual   float64 `json:"actual"`
+	Target   float64 `json:"target"`
+	Currency string  `json:"currency,omitempty"` // Of a spending goal's Actual and Target, the reporting currency
+	Met      bool    `json:"met"`                // The goal would pass if the period ended now
+	Failed   bool    `json:"failed"`             // The goal can no longer pass this period
+	DaysLeft int     `json:"days_left"`          // Days after the current one until the period ends
+}
+
+// GoalStatus is a goal with its current progress and past results, oldest first
+type GoalStatus struct {
+	Goal    Goal         `json:"goal"`
+	Current GoalProgress `json:"current"`
+	History []GoalResult `json:"history"`
+}
+
+// GetGoals returns the saved goals
+func GetGoals(s Store) ([]Goal, error) {
+	goals := []Goal{}
+	_, err := loadSetting(s, goalsKey, &goals)
+	return goals, err
+}
+
+// SaveGoal validates and stores a goal. A goal without an ID is added; one with an
+// ID replaces the saved goal, keeping its creation time and history.
+func SaveGoal(s Store, goal Goal) (Goal, error) {
+	switch goal.Kind {
+	case GoalMaxDrinks, GoalMinAlcoholFreeDays, GoalMaxSpend:
+	default:
+		return goal, fmt.Errorf("unknown goal kind '%s'", goal.Kind)
+	}
+	if _, _, err := PeriodBounds(goal.Period, firstTrackedDate); err != nil {
+		return goal, err
+	}
+	if goal.Target < 0 {
+		return goal, errors.New("goal target must not be negative")
+	}
+	if goal.Kind == GoalMinAlcoholFreeDays && goal.Target == 0 {
+		return goal, errors.New("alcohol-free day goals need at least 1 day")
+	}
+
+	// Spending targets keep their currency, by default the reporting one
+	switch {
+	case goal.Kind != GoalMaxSpend:
+		goal.Currency = ""
+	case goal.Currency == "":
+		currency, err := GetReportingCurrency(s)
+		if err != nil {
+			return goal, err
+		}
+		goal.Currency = currency.Code
+	default:
+		currency, err := LookupCurrency(goal.Currency)
+		if err != nil {
+			return goal, err
+		}
+		goal.Currency = currency.Code
+	}
+
+	added := goal.ID == ""
+	if added {
+		goal.ID = NewID()
+		goal.CreatedAt = time.Now()
+	} else if err := RecordGoalHistory(s); err != nil {
+		// Periods that ended under the old target are judged by it
+		return goal, err
+	}
+
+	goals := []Goal{}
+	err := updateSetting(s, goalsKey, &goals, func() error {
+		if added {
+			goals = append(goals, goal)
+			return nil
+		}
+
+		for i := range goals {
+			if goals[i].ID == goal.ID {
+				// The history is split into periods of this kind
+				if goal.Kind != goals[i].Kind || goal.Period != goals[i].Period {
+					return errors.New("a goal's kind and period can't be changed; add a new goal instead")
+				}
+				goal.CreatedAt = goals[i].CreatedAt
+				goals[i] = goal
+				return nil
+			}
+		}
+		return NotFoundError{Kind: "goal", ID: goal.ID}
+	})
+	return goal, err
+}
+
+// DeleteGoal removes a goal and its history
+func DeleteGoal(s Store, id string) error {
+	goals := []Goal{}
+	err := updateSetting(s, goalsKey, &goals, func() error {
+		for i := range goals {
+			if goals[i].ID == id {
+				goals = append(goals[:i], goals[i+1:]...)
+				return nil
+			}
+		}
+		return NotFoundError{Kind: "goal", ID: id}
+	})
+	if err != nil {
+		return err
+	}
+
+	history := map[string][]GoalResult{}
+	return updateSetting(s, goalHistoryKey, &history, func() error {
+		delete(history, id)
+		return nil
+	})
+}
+
+// getGoalHistory returns the recorded results keyed by goal ID
+func getGoalHistory(s Store) (map[string][]GoalResult, error) {
+	history := map[string][]GoalResult{}
+	_, err := loadSetting(s, goalHistoryKey, &history)
+	return history, err
+}
+
+// goalMeasure picks the figure a goal is measured by out of a period's stats, and
+// returns it with the goal's target in the same units. Spending targets are
+// converted to the currency the stats report spend in.
+func goalMeasure(s Store, goal Goal, stats PeriodStats) (actual, target float64, err error) {
+	switch goal.Kind {
+	case GoalMinAlcoholFreeDays:
+		return float64(stats.AlcoholFreeDays), goal.Target, nil
+	case GoalMaxSpend:
+		target := goal.Target
+		if goal.Currency != "" && goal.Currency != stats.Currency {
+			converted, err := ConvertMoney(s, Money{Amount: int64(math.Round(goal.Target)), Currency: goal.Currency}, stats.Currency)
+			if err != nil {
+				return 0, 0, err
+			}
+			target = float64(converted.Amount)
+		}
+		return float64(stats.Spend), target, nil
+	}
+	return stats.StandardDrinks, goal.Target, nil
+}
+
+// goalCurrency is the currency a goal's figures over a period are in, if any
+func goalCurrency(goal Goal, stats PeriodStats) string {
+	if goal.Kind == GoalMaxSpend {
+		return stats.Currency
+	}
+	return ""
+}
+
+// goalPassed reports whether actual meets a goal's target
+func goalPassed(goal Goal, actual, target float64) bool {
+	switch goal.Kind {
+	case GoalMinAlcoholFreeDays:
+		return actual >= target
+	case GoalMaxSpend:
+		return actual < target
+	}
+	return actual <= target
+}
+
+// finishedGoalResults evaluates the periods of a goal that ended before the one
+// holding today and come after those already recorded
+func finishedGoalResults(s Store, goal Goal, recorded []GoalResult, today Date, rollover int, loc *time.Location) ([]GoalResult, error) {
+	results := []GoalResult{}
+	current, _, err := PeriodBounds(goal.Period, today)
+	if err != nil {
+		return results, err
+	}
+
+	start := DateOf(DrinkingDayOf(goal.CreatedAt, rollover, loc))
+	if len(recorded) > 0 {
+		start = DateOf(recorded[len(recorded)-1].To.Time().AddDate(0, 0, 1))
+	}
+	for start.Before(current) {
+		from, to, _ := PeriodBounds(goal.Period, start)
+		stats, err := GetStats(s, from, to)
+		if err != nil {
+			return results, err
+		}
+
+		actual, target, err := goalMeasure(s, goal, stats)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, GoalResult{
+			GoalID:   goal.ID,
+			From:     from,
+			To:       to,
+			Actual:   actual,
+			Target:   target,
+			Currency: goalCurrency(goal, stats),
+			Passed:   goalPassed(goal, actual, target),
+		})
+		start = DateOf(to.Time().AddDate(0, 0, 1))
+	}
+	return results, nil
+}
+
+// goalClock reads the settings goal periods are judged by
+func goalClock(s Store) (Date, int, *time.Location, error) {
+	loc, err := GetLocation(s)
+	if err != nil {
+		return Date{}, 0, nil, err
+	}
+
+	rollover, err := GetDayRolloverHour(s)
+	if err != nil {
+		return Date{}, 0, nil, err
+	}
+
+	today, err := GetCurrentDrinkingDay(s)
+	return today, rollover, loc, err
+}
+
+// RecordGoalHistory stores the results of goal periods that have ended since
+// they were last recorded, so a goal's history keeps the target and totals it
+// had when each period closed. It runs on startup and before a target changes.
+func RecordGoalHistory(s Store) error {
+	goals, err := GetGoals(s)
+	if err != nil {
+		return err
+	}
+
+	today, rollover, loc, err := goalClock(s)
+	if err != nil {
+		return err
+	}
+
+	history, err := getGoalHistory(s)
+	if err != nil {
+		return err
+	}
+
+	finished := map[string][]GoalResult{}
+	for _, goal := range goals {
+		results, err := finishedGoalResults(s, goal, history[goal.ID], today, rollover, loc)
+		if err != nil {
+			return err
+		}
+		if len(results) > 0 {
+			finished[goal.ID] = results
+		}
+	}
+	if len(finished) == 0 {
+		return nil
+	}
+
+	// Another caller may have recorded some of the same periods in the meantime
+	history = map[string][]GoalResult{}
+	return updateSetting(s, goalHistoryKey, &history, func() error {
+		for id, results := range finished {
+			recorded := history[id]
+			for _, result := range results {
+				if len(recorded) == 0 || recorded[len(recorded)-1].To.Before(result.From) {
+					recorded = append(recorded, result)
+				}
+			}
+			history[id] = recorded
+		}
+		return nil
+	})
+}
+
+// GetGoalStatuses evaluates every goal for the period in progress. Each goal's
+// history holds the recorded results followed by any periods that ended since
+// RecordGoalHistory last ran, judged by the current target. Nothing is written.
+func GetGoalStatuses(s Store) ([]GoalStatus, error) {
+	statuses := []GoalStatus{}
+
+	goals, err := GetGoals(s)
+	if err != nil {
+		return statuses, err
+	}
+
+	today, rollover, loc, err := goalClock(s)
+	if err != nil {
+		return statuses, err
+	}
+
+	history, err := getGoalHistory(s)
+	if err != nil {
+		return statuses, err
+	}
+
+	for _, goal := range goals {
+		from, to, err := PeriodBounds(goal.Period, today)
+		if err != nil {
+			return statuses, err
+		}
+
+		unrecorded, err := finishedGoalResults(s, goal, history[goal.ID], today, rollover, loc)
+		if err != nil {
+			return statuses, err
+		}
+		results := append(append([]GoalResult{}, history[goal.ID]...), unrecorded...)
+
+		stats, err := GetStats(s, from, to)
+		if err != nil {
+			return statuses, err
+		}
+
+		actual, target, err := goalMeasure(s, goal, stats)
+		if err != nil {
+			return statuses, err
+		}
+		progress := GoalProgress{
+			From:     from,
+			To:       to,
+			Actual:   actual,
+			Target:   target,
+			Currency: goalCurrency(goal, stats),
+			DaysLeft: int(to.Time().Sub(today.Time()).Hours() / 24),
+		}
+		progress.Met = goalPassed(goal, actual, target)
+		progress.Failed = !progress.Met
+		if goal.Kind == GoalMinAlcoholFreeDays {
+			// Every remaining day could still be alcohol-free
+			progress.Failed = actual+float64(progress.DaysLeft) < target
+		}
+
+		statuses = append(statuses, GoalStatus{Goal: goal, Current: progress, History: results})
+	}
+	return statuses, nil
+}
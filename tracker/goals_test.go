@@ -0,0 +1,160 @@
+package tracker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSaveGoalConcurrently(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		const n = 20
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(target float64) {
+				defer wg.Done()
+				if _, err := SaveGoal(s, Goal{Kind: GoalMaxDrinks, Period: PeriodWeek, Target: target}); err != nil {
+					t.Error(err)
+				}
+			}(float64(i + 1))
+		}
+		wg.Wait()
+
+		goals, err := GetGoals(s)
+		if err != nil || len(goals) != n {
+			t.Errorf("%d goals saved, %v; want %d", len(goals), err, n)
+		}
+	})
+}
+
+func TestSaveAndDeleteGoal(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		goal, err := SaveGoal(s, Goal{Kind: GoalMaxSpend, Period: PeriodMonth, Target: 5000})
+		if err != nil {
+			t.Fatalf("SaveGoal: %v", err)
+		}
+
+		goal.Period = PeriodWeek
+		if _, err := SaveGoal(s, goal); err == nil {
+			t.Error("a goal's period was changed")
+		}
+		if _, err := SaveGoal(s, Goal{ID: "missing", Kind: GoalMaxDrinks, Period: PeriodWeek, Target: 1}); err == nil {
+			t.Error("saving an unknown goal ID succeeded")
+		}
+		if _, err := SaveGoal(s, Goal{Kind: "max_beers", Period: PeriodWeek, Target: 1}); err == nil {
+			t.Error("an unknown goal kind was accepted")
+		}
+
+		if err := DeleteGoal(s, goal.ID); err != nil {
+			t.Fatalf("DeleteGoal: %v", err)
+		}
+		if err := DeleteGoal(s, goal.ID); err == nil {
+			t.Error("deleting a goal twice succeeded")
+		}
+		if goals, _ := GetGoals(s); len(goals) != 0 {
+			t.Errorf("goals left after deleting: %+v", goals)
+		}
+	})
+}
+
+func TestGoalHistory(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		// A weekly goal set three weeks ago, with one drink in the first week
+		today, err := GetCurrentDrinkingDay(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		monday, _ := WeekOf(today)
+		created := monday.Time().AddDate(0, 0, -21)
+		goal := Goal{ID: NewID(), Kind: GoalMaxDrinks, Period: PeriodWeek, Target: 10, CreatedAt: time.Date(created.Year(), created.Month(), created.Day(), 12, 0, 0, 0, time.Local)}
+		if err := saveSetting(s, goalsKey, []Goal{goal}); err != nil {
+			t.Fatal(err)
+		}
+		mustAdd(t, s, DateOf(created), DayData{Alcohol: "Beer", Quantity: 355})
+
+		statuses, err := GetGoalStatuses(s)
+		if err != nil || len(statuses) != 1 {
+			t.Fatalf("GetGoalStatuses = %+v, %v", statuses, err)
+		}
+		if history := statuses[0].History; len(history) != 3 || history[0].Actual == 0 || !history[0].Passed {
+			t.Errorf("history = %+v, want three passed weeks, the first with a drink", history)
+		}
+		if value, _ := s.GetSetting(goalHistoryKey); value != nil {
+			t.Errorf("reading statuses recorded history: %s", value)
+		}
+
+		// Lowering the target records the finished weeks under the old one first
+		goal.Target = 0
+		if _, err := SaveGoal(s, goal); err != nil {
+			t.Fatalf("SaveGoal: %v", err)
+		}
+		statuses, err = GetGoalStatuses(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history := statuses[0].History
+		if len(history) != 3 || history[0].Target != 10 || !history[0].Passed {
+			t.Errorf("history = %+v, want the three weeks judged against 10", history)
+		}
+
+		// Recording again adds nothing
+		if err := RecordGoalHistory(s); err != nil {
+			t.Fatal(err)
+		}
+		recorded, _ := getGoalHistory(s)
+		if len(recorded[goal.ID]) != 3 {
+			t.Errorf("%d results recorded, want 3", len(recorded[goal.ID]))
+		}
+
+		if err := DeleteGoal(s, goal.ID); err != nil {
+			t.Fatal(err)
+		}
+		if recorded, _ := getGoalHistory(s); len(recorded) != 0 {
+			t.Errorf("history left after deleting the goal: %+v", recorded)
+		}
+	})
+}
+
+func TestSpendGoalCurrency(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if goal, err := SaveGoal(s, Goal{Kind: GoalMaxSpend, Period: PeriodMonth, Target: 5000}); err != nil || goal.Currency != USDollar.Code {
+			t.Errorf("SaveGoal = %+v, %v; want the target in the reporting currency", goal, err)
+		}
+		if goal, err := SaveGoal(s, Goal{Kind: GoalMaxDrinks, Period: PeriodMonth, Target: 5, Currency: "EUR"}); err != nil || goal.Currency != "" {
+			t.Errorf("SaveGoal = %+v, %v; want no currency on a drinks goal", goal, err)
+		}
+		if _, err := SaveGoal(s, Goal{Kind: GoalMaxSpend, Period: PeriodMonth, Target: 5000, Currency: "XXX"}); err == nil {
+			t.Error("a spending goal in an unknown currency was accepted")
+		}
+		if err := saveSetting(s, goalsKey, []Goal{}); err != nil {
+			t.Fatal(err)
+		}
+
+		// 30 EUR is 45 USD, so 40 USD spent is within it
+		goal, err := SaveGoal(s, Goal{Kind: GoalMaxSpend, Period: PeriodMonth, Target: 3000, Currency: "eur"})
+		if err != nil || goal.Currency != "EUR" {
+			t.Fatalf("SaveGoal = %+v, %v; want the target in EUR", goal, err)
+		}
+		today, err := GetCurrentDrinkingDay(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mustAdd(t, s, today, DayData{Alcohol: "Wine", Quantity: 750, Cost: 4000, Currency: "USD"})
+
+		if _, err := GetGoalStatuses(s); err == nil {
+			t.Error("a spending goal was judged without an exchange rate for its currency")
+		}
+		if err := SetExchangeRate(s, "EUR", "USD", 1.5); err != nil {
+			t.Fatal(err)
+		}
+		statuses, err := GetGoalStatuses(s)
+		if err != nil || len(statuses) != 1 {
+			t.Fatalf("GetGoalStatuses = %+v, %v", statuses, err)
+		}
+		current := statuses[0].Current
+		if current.Actual != 4000 || current.Target != 4500 || current.Currency != "USD" || !current.Met {
+			t.Errorf("current = %+v, want 4000 of 4500 USD spent and the goal met", current)
+		}
+	})
+}
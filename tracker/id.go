@@ -0,0 +1,44 @@
+package tracker
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// Crockford's base32 alphabet, as used by ULIDs
+const idAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewID generates a unique, lexically sortable entry ID (ULID layout)
+func NewID() string {
+	return newIDAt(time.Now())
+}
+
+// newIDAt generates an ID whose time component is t. The first 48 bits hold
+// the Unix time in milliseconds and the remaining 80 bits are random.
+func newIDAt(t time.Time) string {
+	var raw [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		raw[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(raw[6:]); err != nil {
+		panic("tracker: failed to read random bytes: " + err.Error())
+	}
+
+	// Encode 128 bits as 26 base32 characters, most significant first
+	var out [26]byte
+	var acc uint32
+	bits := 2 // pad the front so 130 bits divide evenly into 5-bit groups
+	pos := 0
+	for _, b := range raw {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = idAlphabet[(acc>>uint(bits))&0x1f]
+			pos++
+		}
+	}
+	return string(out[:])
+}
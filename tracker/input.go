@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EntryInput is a drink to log as people enter it, shared by the app, the CLI
+// and the REST API
+type EntryInput struct {
+	Date     Date    // Calendar date to store the drink under; the zero Date means the date now
+	Time     string  // "HH:MM" the drink was had; "" for now, or noon on past dates
+	Drink    string  // Must be in the catalog
+	Quantity float64 // In Unit
+	Unit     string  // Unit code of Quantity; "" for mL
+	Cost     float64 // In major units (e.g. dollars) of Currency
+	Currency string  // "" for the default currency
+}
+
+// EntryPatch lists the fields of an entry to change; nil fields are kept
+type EntryPatch struct {
+	Date     *Date
+	Time     *string
+	Drink    *string // A different drink takes its ABV from the catalog
+	Quantity *float64
+	Unit     string // Unit code of Quantity; "" for mL
+	Cost     *float64
+	Currency *string // Without Cost, the amount is kept in the new currency
+}
+
+// ParseDate reads a YYYY-MM-DD date, or "", "today" or "yesterday" relative to
+// the current drinking day
+func ParseDate(s Store, value string) (Date, error) {
+	switch value {
+	case "", "today", "yesterday":
+		today, err := GetCurrentDrinkingDay(s)
+		if err != nil || value != "yesterday" {
+			return today, err
+		}
+		return DateOf(today.Time().AddDate(0, 0, -1)), nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date '%s': expected YYYY-MM-DD", value)
+	}
+	date := DateOf(parsed)
+	return date, ValidateDate(date.Day, date.Month, date.Year)
+}
+
+// quantityInML checks a quantity and converts it from a unit code to mL
+func quantityInML(quantity float64, unit string) (float64, error) {
+	if quantity <= 0 {
+		return 0, errors.New("quantity must be above 0")
+	}
+
+	volumeUnit := Milliliters
+	if unit != "" {
+		var err error
+		if volumeUnit, err = LookupVolumeUnit(unit); err != nil {
+			return 0, err
+		}
+	}
+	return volumeUnit.ToML(quantity), nil
+}
+
+// LogEntry validates and stores a new drink at the time now, returning it as
+// stored. A drink without a time logged on the current drinking day was had
+// now, so it is stored under the calendar date now: between midnight and the
+// rollover hour that is the date after the drinking day.
+func LogEntry(s Store, input EntryInput, now time.Time) (Record, error) {
+	if input.Drink == "" {
+		return Record{}, errors.New("drink must not be empty")
+	}
+	if input.Cost < 0 {
+		return Record{}, errors.New("cost must not be negative")
+	}
+
+	quantity, err := quantityInML(input.Quantity, input.Unit)
+	if err != nil {
+		return Record{}, err
+	}
+
+	currency, err := GetDefaultCurrency(s)
+	if input.Currency != "" {
+		currency, err = LookupCurrency(input.Currency)
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	loc, err := GetLocation(s)
+	if err != nil {
+		return Record{}, err
+	}
+
+	rollover, err := GetDayRolloverHour(s)
+	if err != nil {
+		return Record{}, err
+	}
+
+	now = now.In(loc)
+	date := input.Date
+	if date == (Date{}) || (input.Time == "" && date == DateOf(DrinkingDayOf(now, rollover, loc))) {
+		date = DateOf(now)
+	}
+	if err := ValidateDate(date.Day, date.Month, date.Year); err != nil {
+		return Record{}, err
+	}
+
+	consumedAt := now
+	if input.Time != "" || date != DateOf(now) {
+		if consumedAt, err = ConsumedAtOn(date.Year, date.Month, date.Day, input.Time, loc); err != nil {
+			return Record{}, err
+		}
+	}
+
+	// The ID is set here so the stored entry can be read back
+	entry := DayData{
+		ID:         NewID(),
+		Alcohol:    input.Drink,
+		Quantity:   quantity,
+		Cost:       currency.ToMinor(input.Cost),
+		Currency:   currency.Code,
+		ConsumedAt: consumedAt,
+	}
+	if err := AddEntry(s, date.Year, date.Month, date.Day, entry); err != nil {
+		return Record{}, err
+	}
+
+	entry, err = s.GetEntry(date.Year, date.Month, date.Day, entry.ID)
+	return Record{Date: date, Category: entry.Alcohol, Entry: entry}, err
+}
+
+// EditEntry applies a patch to the entry with the given ID on a date, returning
+// it as stored. Its time of day is kept when it moves to another date.
+func EditEntry(s Store, date Date, id string, patch EntryPatch) (Record, error) {
+	entry, err := s.GetEntry(date.Year, date.Month, date.Day, id)
+	if err != nil {
+		return Record{}, err
+	}
+
+	if patch.Drink != nil && *patch.Drink != entry.Alcohol {
+		if *patch.Drink == "" {
+			return Record{}, errors.New("drink must not be empty")
+		}
+		entry.Alcohol = *patch.Drink
+		entry.ABV = 0
+	}
+
+	if patch.Quantity != nil {
+		if entry.Quantity, err = quantityInML(*patch.Quantity, patch.Unit); err != nil {
+			return Record{}, err
+		}
+	}
+
+	if patch.Cost != nil || patch.Currency != nil {
+		oldCurrency, err := LookupCurrency(entry.Currency)
+		if err != nil {
+			return Record{}, err
+		}
+		newCurrency := oldCurrency
+		if patch.Currency != nil {
+			if newCurrency, err = LookupCurrency(*patch.Currency); err != nil {
+				return Record{}, err
+			}
+		}
+
+		amount := oldCurrency.FromMinor(entry.Cost)
+		if patch.Cost != nil {
+			if *patch.Cost < 0 {
+				return Record{}, errors.New("cost must not be negative")
+			}
+			amount = *patch.Cost
+		}
+		entry.Cost = newCurrency.ToMinor(amount)
+		entry.Currency = newCurrency.Code
+	}
+
+	newDate := date
+	if patch.Date != nil {
+		newDate = *patch.Date
+	}
+
+	// Stored times only keep their UTC offset, which may not hold on the new date
+	loc, err := GetLocation(s)
+	if err != nil {
+		return Record{}, err
+	}
+	clock := entry.ConsumedAt.In(loc).Format("15:04")
+	if patch.Time != nil {
+		clock = *patch.Time
+	}
+	entry.ConsumedAt, err = ConsumedAtOn(newDate.Year, newDate.Month, newDate.Day, clock, loc)
+	if err != nil {
+		return Record{}, err
+	}
+
+	err = UpdateEntry(s, date.Year, date.Month, date.Day, id, newDate.Year, newDate.Month, newDate.Day, entry)
+	if err != nil {
+		return Record{}, err
+	}
+
+	entry, err = s.GetEntry(newDate.Year, newDate.Month, newDate.Day, id)
+	return Record{Date: newDate, Category: entry.Alcohol, Entry: entry}, err
+}
@@ -0,0 +1,121 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogEntryDate(t *testing.T) {
+	s := NewMemoryStore()
+	if err := SetTimezone(s, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDayRolloverHour(s, 5); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 3, 6, 1, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		date     Date
+		time     string
+		wantDate Date
+		wantAt   time.Time
+	}{
+		{Date{}, "", Date{2024, 3, 6}, now},
+		{Date{2024, 3, 5}, "", Date{2024, 3, 6}, now},
+		{Date{2024, 3, 5}, "23:00", Date{2024, 3, 5}, time.Date(2024, 3, 5, 23, 0, 0, 0, time.UTC)},
+		{Date{2024, 3, 1}, "", Date{2024, 3, 1}, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		record, err := LogEntry(s, EntryInput{Date: test.date, Time: test.time, Drink: "Beer", Quantity: 500}, now)
+		if err != nil {
+			t.Fatalf("LogEntry(%s %q): %v", test.date, test.time, err)
+		}
+		if record.Date != test.wantDate || !record.Entry.ConsumedAt.Equal(test.wantAt) {
+			t.Errorf("LogEntry(%s %q) stored on %s at %v, want %s at %v",
+				test.date, test.time, record.Date, record.Entry.ConsumedAt, test.wantDate, test.wantAt)
+		}
+	}
+}
+
+func TestEditEntryKeepsTime(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		date := Date{Year: 2024, Month: 3, Day: 5}
+		entry := mustAdd(t, s, date, DayData{Alcohol: "Beer", Quantity: 500, Cost: 450, Currency: "USD",
+			ConsumedAt: time.Date(2024, 3, 5, 21, 15, 0, 0, time.UTC)})
+
+		moved := Date{Year: 2024, Month: 3, Day: 2}
+		currency := "EUR"
+		record, err := EditEntry(s, date, entry.ID, EntryPatch{Date: &moved, Currency: &currency})
+		if err != nil {
+			t.Fatalf("EditEntry: %v", err)
+		}
+		if want := time.Date(2024, 3, 2, 21, 15, 0, 0, time.UTC); !record.Entry.ConsumedAt.Equal(want) {
+			t.Errorf("ConsumedAt = %v, want %v", record.Entry.ConsumedAt, want)
+		}
+		if record.Entry.Cost != 450 || record.Entry.Currency != "EUR" {
+			t.Errorf("cost = %d %s, want the amount kept as 450 EUR", record.Entry.Cost, record.Entry.Currency)
+		}
+
+		unknown := "Moonshine"
+		if _, err := EditEntry(s, moved, entry.ID, EntryPatch{Drink: &unknown}); err == nil {
+			t.Error("EditEntry changed the drink to one without an ABV")
+		}
+	})
+}
+
+func TestEditEntryAcrossDST(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "Europe/London"); err != nil {
+			t.Fatal(err)
+		}
+		loc, err := GetLocation(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Clocks go forward on 31 March 2024, from GMT to BST
+		date := Date{Year: 2024, Month: 3, Day: 30}
+		entry := mustAdd(t, s, date, DayData{Alcohol: "Beer", Quantity: 500,
+			ConsumedAt: time.Date(2024, 3, 30, 21, 15, 0, 0, loc)})
+
+		moved := Date{Year: 2024, Month: 4, Day: 2}
+		record, err := EditEntry(s, date, entry.ID, EntryPatch{Date: &moved})
+		if err != nil {
+			t.Fatalf("EditEntry: %v", err)
+		}
+		if want := time.Date(2024, 4, 2, 21, 15, 0, 0, loc); !record.Entry.ConsumedAt.Equal(want) {
+			t.Errorf("ConsumedAt = %v, want %v", record.Entry.ConsumedAt, want)
+		}
+
+		// And back again, with a new time
+		clock := "22:30"
+		record, err = EditEntry(s, moved, entry.ID, EntryPatch{Date: &date, Time: &clock})
+		if err != nil {
+			t.Fatalf("EditEntry: %v", err)
+		}
+		if want := time.Date(2024, 3, 30, 22, 30, 0, 0, loc); !record.Entry.ConsumedAt.Equal(want) {
+			t.Errorf("ConsumedAt = %v, want %v", record.Entry.ConsumedAt, want)
+		}
+	})
+}
+
+func TestParseDate(t *testing.T) {
+	s := NewMemoryStore()
+	today, err := GetCurrentDrinkingDay(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if date, err := ParseDate(s, "yesterday"); err != nil || date != DateOf(today.Time().AddDate(0, 0, -1)) {
+		t.Errorf("ParseDate(yesterday) = %s, %v; want the day before %s", date, err, today)
+	}
+	if date, err := ParseDate(s, "2024-02-29"); err != nil || date != (Date{2024, 2, 29}) {
+		t.Errorf("ParseDate(2024-02-29) = %s, %v", date, err)
+	}
+	for _, value := range []string{"2023-02-29", "29/02/2024", "tomorrow"} {
+		if _, err := ParseDate(s, value); err == nil {
+			t.Errorf("ParseDate(%s) succeeded", value)
+		}
+	}
+}
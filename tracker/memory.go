@@ -0,0 +1,368 @@
+package tracker
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MemoryStore keeps entries in memory with the same Year → Month → Day → Category
+// layout as BoltStore. It is meant for tests and throwaway sessions.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	days     map[string]map[string][]DayData // Structure: "YYYY-MM-DD" → Category → Entries
+	catalog  map[string]Drink
+	settings map[string][]byte
+}
+
+// NewMemoryStore creates an in-memory store with no entries and the default catalog
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		days:     make(map[string]map[string][]DayData),
+		catalog:  make(map[string]Drink),
+		settings: make(map[string][]byte),
+	}
+	for _, drink := range defaultDrinks {
+		s.catalog[drink.Name] = drink
+	}
+	return s
+}
+
+func dayKey(year, month, day int) string {
+	return fmt.Sprintf("%d-%02d-%02d", year, month, day)
+}
+
+// copyDay returns a copy of a day's entries so callers cannot alias the store's slices
+func copyDay(categories map[string][]DayData) map[string][]DayData {
+	out := make(map[string][]DayData, len(categories))
+	for category, entries := range categories {
+		out[category] = append([]DayData(nil), entries...)
+	}
+	return out
+}
+
+func (s *MemoryStore) AddEntry(year, month, day int, category string, data DayData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if data.ID == "" {
+		data.ID = NewID()
+	}
+
+	key := dayKey(year, month, day)
+	if s.days[key] == nil {
+		s.days[key] = make(map[string][]DayData)
+	}
+	s.days[key][category] = append(s.days[key][category], data)
+	return nil
+}
+
+func (s *MemoryStore) GetEntriesByDate(year, month, day int) (map[string][]DayData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	categories, ok := s.days[dayKey(year, month, day)]
+	if !ok {
+		return nil, fmt.Errorf("no data found for day %02d in month %02d of year %d", day, month, year)
+	}
+	return copyDay(categories), nil
+}
+
+// findEntry locates the category and index of the entry with the given ID on a day
+func (s *MemoryStore) findEntry(year, month, day int, id string) (string, int, error) {
+	for category, entries := range s.days[dayKey(year, month, day)] {
+		for i, entry := range entries {
+			if entry.ID == id {
+				return category, i, nil
+			}
+		}
+	}
+	return "", -1, NotFoundError{Kind: "entry", ID: id}
+}
+
+func (s *MemoryStore) GetEntry(year, month, day int, id string) (DayData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	category, i, err := s.findEntry(year, month, day, id)
+	if err != nil {
+		return DayData{}, err
+	}
+	return s.days[dayKey(year, month, day)][category][i], nil
+}
+
+func (s *MemoryStore) UpdateEntry(year, month, day int, id string, newYear, newMonth, newDay int, data DayData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category, i, err := s.findEntry(year, month, day, id)
+	if err != nil {
+		return err
+	}
+
+	data.ID = id
+	if data.Alcohol == "" {
+		data.Alcohol = category
+	}
+
+	key := dayKey(year, month, day)
+	newKey := dayKey(newYear, newMonth, newDay)
+	if key == newKey && data.Alcohol == category {
+		s.days[key][category][i] = data
+		return nil
+	}
+
+	s.removeAt(key, category, i)
+	if s.days[newKey] == nil {
+		s.days[newKey] = make(map[string][]DayData)
+	}
+	s.days[newKey][data.Alcohol] = append(s.days[newKey][data.Alcohol], data)
+	return nil
+}
+
+// removeAt drops the i-th entry of a category, deleting the category and then
+// the day once they are empty
+func (s *MemoryStore) removeAt(key, category string, i int) {
+	entries := s.days[key][category]
+	if len(entries) == 1 {
+		delete(s.days[key], category)
+	} else {
+		s.days[key][category] = append(entries[:i:i], entries[i+1:]...)
+	}
+	if len(s.days[key]) == 0 {
+		delete(s.days, key)
+	}
+}
+
+func (s *MemoryStore) DeleteEntry(year, month, day int, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category, i, err := s.findEntry(year, month, day, id)
+	if err != nil {
+		return err
+	}
+
+	s.removeAt(dayKey(year, month, day), category, i)
+	return nil
+}
+
+func (s *MemoryStore) GetEntriesByYear(year int) (map[string]map[string]map[string][]DayData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make(map[string]map[string]map[string][]DayData)
+	for key, categories := range s.days {
+		var y, m, d int
+		fmt.Sscanf(key, "%d-%d-%d", &y, &m, &d)
+		if y != year {
+			continue
+		}
+
+		monthStr := fmt.Sprintf("%02d", m)
+		if entries[monthStr] == nil {
+			entries[monthStr] = make(map[string]map[string][]DayData)
+		}
+		entries[monthStr][fmt.Sprintf("%02d", d)] = copyDay(categories)
+	}
+
+	if len(entries) == 0 {
+		return entries, fmt.Errorf("no data found for year %d", year)
+	}
+	return entries, nil
+}
+
+func (s *MemoryStore) GetEntriesByYearAndMonth(year, month int) (map[string]map[string][]DayData, error) {
+	months, err := s.GetEntriesByYear(year)
+	if err != nil {
+		return make(map[string]map[string][]DayData), err
+	}
+
+	days, ok := months[fmt.Sprintf("%02d", month)]
+	if !ok {
+		return make(map[string]map[string][]DayData), fmt.Errorf("no data found for month %02d in year %d", month, year)
+	}
+	return days, nil
+}
+
+func (s *MemoryStore) GetRecordsInRange(from, to Date) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := []Record{}
+	fromKey, toKey := from.String(), to.String()
+	for _, key := range sortedKeys(s.days) {
+		if key < fromKey || key > toKey {
+			continue
+		}
+
+		var date Date
+		fmt.Sscanf(key, "%d-%d-%d", &date.Year, &date.Month, &date.Day)
+		categories := s.days[key]
+		for _, category := range sortedKeys(categories) {
+			for _, entry := range categories[category] {
+				records = append(records, Record{Date: date, Category: category, Entry: entry})
+			}
+		}
+	}
+	return records, nil
+}
+
+func (s *MemoryStore) FindLatestEntryDate() (int, int, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Keys are zero-padded, so the lexically greatest key is the latest date
+	latest := ""
+	for key := range s.days {
+		if key > latest {
+			latest = key
+		}
+	}
+	if latest == "" {
+		return 0, 0, 0, fmt.Errorf("no data found in database")
+	}
+
+	var year, month, day int
+	fmt.Sscanf(latest, "%d-%d-%d", &year, &month, &day)
+	return year, month, day, nil
+}
+
+func (s *MemoryStore) GetDrinks() ([]Drink, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	drinks := make([]Drink, 0, len(s.catalog))
+	for _, name := range sortedKeys(s.catalog) {
+		drinks = append(drinks, s.catalog[name])
+	}
+	return drinks, nil
+}
+
+func (s *MemoryStore) SaveDrink(drink Drink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.catalog[drink.Name] = drink
+	return nil
+}
+
+func (s *MemoryStore) GetSetting(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if value, ok := s.settings[key]; ok {
+		return append([]byte(nil), value...), nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) PutSetting(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *MemoryStore) UpdateSetting(key string, update func(value []byte) ([]byte, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current []byte
+	if value, ok := s.settings[key]; ok {
+		current = append([]byte(nil), value...)
+	}
+
+	value, err := update(current)
+	if err != nil {
+		return err
+	}
+	s.settings[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *MemoryStore) GetSettings() (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings := make(map[string][]byte, len(s.settings))
+	for key, value := range s.settings {
+		settings[key] = append([]byte(nil), value...)
+	}
+	return settings, nil
+}
+
+func (s *MemoryStore) Restore(backup Backup, replace bool) (RestoreResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result RestoreResult
+	if replace {
+		s.days = make(map[string]map[string][]DayData)
+		s.catalog = make(map[string]Drink)
+		settings := make(map[string][]byte)
+		for key := range localSettings {
+			if value, ok := s.settings[key]; ok {
+				settings[key] = value
+			}
+		}
+		s.settings = settings
+	}
+
+	// An entry may have been moved to another date since the backup was made
+	stored := map[string]bool{}
+	for _, day := range s.days {
+		for _, entries := range day {
+			for _, entry := range entries {
+				stored[entry.ID] = true
+			}
+		}
+	}
+
+	for _, record := range backup.Entries {
+		if stored[record.Entry.ID] {
+			result.SkippedEntries++
+			continue
+		}
+		stored[record.Entry.ID] = true
+
+		date := record.Date
+		key := dayKey(date.Year, date.Month, date.Day)
+		if s.days[key] == nil {
+			s.days[key] = make(map[string][]DayData)
+		}
+		s.days[key][record.Category] = append(s.days[key][record.Category], record.Entry)
+		result.Entries++
+	}
+
+	for _, drink := range backup.Catalog {
+		if _, ok := s.catalog[drink.Name]; ok {
+			result.SkippedDrinks++
+			continue
+		}
+		s.catalog[drink.Name] = drink
+		result.Drinks++
+	}
+
+	for key, value := range backup.Settings {
+		if current, ok := s.settings[key]; ok {
+			merged, changed, err := mergeSetting(key, current, value)
+			if err != nil {
+				return result, fmt.Errorf("failed to merge setting %s: %v", key, err)
+			}
+			if !changed {
+				result.SkippedSettings++
+				continue
+			}
+			value = merged
+		}
+		s.settings[key] = append([]byte(nil), value...)
+		result.Settings++
+	}
+
+	return result, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
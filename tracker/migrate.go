@@ -0,0 +1,179 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	metaBucket       = []byte("Meta")
+	schemaVersionKey = []byte("schema_version")
+)
+
+// errDryRun rolls back the migration transaction in dry-run mode
+var errDryRun = errors.New("dry run")
+
+// Migration upgrades the database from Version-1 to Version inside a write transaction
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(tx *bbolt.Tx) error
+}
+
+// migrations is the ordered registry of schema changes. Append new migrations with
+// the next version number; never edit or reorder ones that have shipped.
+var migrations = []Migration{
+	{Version: 1, Description: "assign IDs to entries", Apply: assignMissingIDs},
+	{Version: 2, Description: "seed drink catalog", Apply: seedCatalog},
+	{Version: 3, Description: "store ABV and alcohol grams on entries", Apply: backfillAlcohol},
+	{Version: 4, Description: "record consumption and creation times", Apply: backfillTimes},
+	{Version: 5, Description: "remove empty days", Apply: pruneEmptyDays},
+	{Version: 6, Description: "store costs in minor units with a currency", Apply: convertCosts},
+	{Version: 7, Description: "record the currency of spending goal targets", Apply: stampGoalCurrencies},
+}
+
+// CurrentSchemaVersion is the schema version written by this build
+func CurrentSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// getSchemaVersion reads the version from the meta bucket; a missing marker means version 0
+func getSchemaVersion(tx *bbolt.Tx) (int, error) {
+	meta := tx.Bucket(metaBucket)
+	if meta == nil {
+		return 0, nil
+	}
+
+	value := meta.Get(schemaVersionKey)
+	if value == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(string(value))
+}
+
+func setSchemaVersion(tx *bbolt.Tx, version int) error {
+	meta, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	return meta.Put(schemaVersionKey, []byte(strconv.Itoa(version)))
+}
+
+// pendingMigrations returns the migrations newer than version, in order
+func pendingMigrations(version int) []Migration {
+	pending := []Migration{}
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Migrate runs all pending migrations in a single transaction. Before writing,
+// a copy of the file is saved next to path as "<path>.v<version>.bak". With
+// dryRun set, the migrations are applied and then rolled back, so the caller
+// can see what would run without touching the file.
+func Migrate(db *bbolt.DB, path string, dryRun bool) ([]Migration, error) {
+	var pending []Migration
+
+	err := db.View(func(tx *bbolt.Tx) error {
+		version, err := getSchemaVersion(tx)
+		if err != nil {
+			return fmt.Errorf("invalid schema version: %v", err)
+		}
+		if version > CurrentSchemaVersion() {
+			return fmt.Errorf("database schema version %d is newer than supported version %d", version, CurrentSchemaVersion())
+		}
+
+		pending = pendingMigrations(version)
+		if len(pending) == 0 || dryRun || path == "" {
+			return nil
+		}
+
+		// A fresh file has nothing worth backing up
+		if tx.Bucket(trackerBucket) == nil {
+			return nil
+		}
+
+		// Take the backup inside the same read transaction that saw the old version
+		backupPath := fmt.Sprintf("%s.v%d.bak", path, version)
+		log.Printf("Backing up database to %s", backupPath)
+		return tx.CopyFile(backupPath, 0600)
+	})
+	if err != nil || len(pending) == 0 {
+		return pending, err
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, m := range pending {
+			if err := m.Apply(tx); err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %v", m.Version, m.Description, err)
+			}
+			log.Printf("Applied migration %d: %s", m.Version, m.Description)
+		}
+
+		if err := setSchemaVersion(tx, CurrentSchemaVersion()); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err == errDryRun {
+		err = nil
+	}
+
+	return pending, err
+}
+
+// DryRunMigrations opens the database at path read-only and reports the
+// migrations that InitDB would apply, without changing the file
+func DryRunMigrations(path string) ([]Migration, error) {
+	// A read-only open would still create a missing file
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{ReadOnly: true, Timeout: dbOpenTimeout})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, fmt.Errorf("%s is in use by another process", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var pending []Migration
+	err = db.View(func(tx *bbolt.Tx) error {
+		version, err := getSchemaVersion(tx)
+		if err != nil {
+			return fmt.Errorf("invalid schema version: %v", err)
+		}
+		if version > CurrentSchemaVersion() {
+			return fmt.Errorf("database schema version %d is newer than supported version %d", version, CurrentSchemaVersion())
+		}
+
+		pending = pendingMigrations(version)
+		return nil
+	})
+	return pending, err
+}
+
+// SchemaVersion returns the schema version recorded in the database
+func (s *BoltStore) SchemaVersion() (int, error) {
+	var version int
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		version, err = getSchemaVersion(tx)
+		return err
+	})
+	return version, err
+}
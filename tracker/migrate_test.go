@@ -0,0 +1,318 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Times of the entries in the v0 fixture
+var (
+	fixtureEvening = time.Date(2023, 6, 10, 21, 0, 0, 0, time.Local)
+	fixtureLater   = time.Date(2023, 6, 12, 9, 30, 0, 0, time.Local) // Logged a day after the date it was put under
+)
+
+// writeV0Fixture creates a database laid out as the first release wrote it: no
+// schema version, no catalog, entries without IDs holding a float cost and a
+// Unix timestamp, a day left empty by a delete, and money settings in dollars
+func writeV0Fixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), DefaultDBFile)
+
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("bbolt.Open: %v", err)
+	}
+	defer db.Close()
+
+	days := []struct {
+		date    Date
+		entries map[string]string
+	}{
+		{Date{Year: 2023, Month: 6, Day: 10}, map[string]string{
+			"Beer": fmt.Sprintf(`[{"alcohol":"Beer","quantity":500,"cost":6.5,"timestamp":%d}]`, fixtureEvening.Unix()),
+			"Wine": fmt.Sprintf(`[{"alcohol":"Wine","quantity":150,"cost":9,"timestamp":%d}]`, fixtureEvening.Unix()),
+		}},
+		{Date{Year: 2023, Month: 6, Day: 11}, map[string]string{
+			"Moonshine": fmt.Sprintf(`[{"alcohol":"Moonshine","quantity":50,"timestamp":%d}]`, fixtureLater.Unix()),
+		}},
+		{Date{Year: 2023, Month: 7, Day: 1}, nil},
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, day := range days {
+			dayBucket, err := createDayBucket(tx, day.date.Year, day.date.Month, day.date.Day)
+			if err != nil {
+				return err
+			}
+			for category, value := range day.entries {
+				if err := dayBucket.Put([]byte(category), []byte(value)); err != nil {
+					return err
+				}
+			}
+		}
+
+		settings, err := tx.CreateBucket(settingsBucket)
+		if err != nil {
+			return err
+		}
+		if err := settings.Put([]byte(monthlyBudgetKey), []byte("100.5")); err != nil {
+			return err
+		}
+		goals := `[{"id":"g1","kind":"max_spend","period":"month","target":50},{"id":"g2","kind":"max_drinks","period":"week","target":10}]`
+		return settings.Put([]byte(goalsKey), []byte(goals))
+	})
+	if err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	return path
+}
+
+// openFixture opens a fixture file, closing it after the test
+func openFixture(t *testing.T, path string) *bbolt.DB {
+	t.Helper()
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("bbolt.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// readFixture returns every stored entry by category, and the dates that have a day bucket
+func readFixture(t *testing.T, db *bbolt.DB) (map[string]DayData, []Date) {
+	t.Helper()
+	entries := map[string]DayData{}
+	dates := []Date{}
+
+	err := db.View(func(tx *bbolt.Tx) error {
+		return forEachDayBucket(tx, func(year, month, day int, dayBucket *bbolt.Bucket) error {
+			dates = append(dates, Date{Year: year, Month: month, Day: day})
+			byCategory, err := readDay(dayBucket)
+			if err != nil {
+				return err
+			}
+			for category, categoryEntries := range byCategory {
+				entries[category] = categoryEntries[0]
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return entries, dates
+}
+
+// readFixtureSetting decodes a settings value from a fixture
+func readFixtureSetting(t *testing.T, db *bbolt.DB, key string, v interface{}) {
+	t.Helper()
+	err := db.View(func(tx *bbolt.Tx) error {
+		return json.Unmarshal(tx.Bucket(settingsBucket).Get([]byte(key)), v)
+	})
+	if err != nil {
+		t.Fatalf("reading setting %s: %v", key, err)
+	}
+}
+
+// TestMigrations applies each migration in turn to the v0 fixture and checks what it changed
+func TestMigrations(t *testing.T) {
+	db := openFixture(t, writeV0Fixture(t))
+
+	checks := map[int]func(t *testing.T){
+		1: func(t *testing.T) {
+			entries, _ := readFixture(t, db)
+			seen := map[string]bool{}
+			for category, entry := range entries {
+				if entry.ID == "" || seen[entry.ID] {
+					t.Errorf("%s has ID %q, want a new unique one", category, entry.ID)
+				}
+				seen[entry.ID] = true
+			}
+		},
+		2: func(t *testing.T) {
+			err := db.View(func(tx *bbolt.Tx) error {
+				if n := tx.Bucket(catalogBucket).Stats().KeyN; n != len(defaultDrinks) {
+					t.Errorf("catalog has %d drinks, want %d", n, len(defaultDrinks))
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		},
+		3: func(t *testing.T) {
+			entries, _ := readFixture(t, db)
+			for category, abv := range map[string]float64{"Beer": 5, "Wine": 12, "Moonshine": fallbackABV} {
+				entry := entries[category]
+				if entry.ABV != abv || entry.AlcoholGrams != AlcoholGrams(entry.Quantity, abv) {
+					t.Errorf("%s: ABV %g and %g g, want %g%%", category, entry.ABV, entry.AlcoholGrams, abv)
+				}
+			}
+		},
+		4: func(t *testing.T) {
+			entries, _ := readFixture(t, db)
+			if beer := entries["Beer"]; !beer.ConsumedAt.Equal(fixtureEvening) || !beer.CreatedAt.Equal(fixtureEvening) {
+				t.Errorf("Beer consumed at %v and created at %v, want both at %v", beer.ConsumedAt, beer.CreatedAt, fixtureEvening)
+			}
+			noon := time.Date(2023, 6, 11, 12, 0, 0, 0, time.Local)
+			if late := entries["Moonshine"]; !late.ConsumedAt.Equal(noon) || !late.CreatedAt.Equal(fixtureLater) {
+				t.Errorf("Moonshine consumed at %v and created at %v, want %v and %v", late.ConsumedAt, late.CreatedAt, noon, fixtureLater)
+			}
+			for category, entry := range entries {
+				if entry.Timestamp != 0 {
+					t.Errorf("%s still has its legacy timestamp", category)
+				}
+			}
+		},
+		5: func(t *testing.T) {
+			_, dates := readFixture(t, db)
+			for _, date := range dates {
+				if date == (Date{Year: 2023, Month: 7, Day: 1}) {
+					t.Errorf("the empty day %s is still stored", date)
+				}
+			}
+			if len(dates) != 2 {
+				t.Errorf("%d days are stored, want 2", len(dates))
+			}
+		},
+		6: func(t *testing.T) {
+			entries, _ := readFixture(t, db)
+			for category, cost := range map[string]int64{"Beer": 650, "Wine": 900, "Moonshine": 0} {
+				entry := entries[category]
+				if entry.Cost != cost || entry.Currency != USDollar.Code || entry.LegacyCost != 0 {
+					t.Errorf("%s costs %d %s (legacy %g), want %d USD", category, entry.Cost, entry.Currency, entry.LegacyCost, cost)
+				}
+			}
+
+			var budget Money
+			readFixtureSetting(t, db, monthlyBudgetKey, &budget)
+			if budget != (Money{Amount: 10050, Currency: USDollar.Code}) {
+				t.Errorf("monthly budget = %+v, want 10050 USD", budget)
+			}
+
+			var goals []Goal
+			readFixtureSetting(t, db, goalsKey, &goals)
+			if len(goals) != 2 || goals[0].Target != 5000 || goals[1].Target != 10 {
+				t.Errorf("goals = %+v, want the spending target in cents and the drinks target unchanged", goals)
+			}
+		},
+		7: func(t *testing.T) {
+			var goals []Goal
+			readFixtureSetting(t, db, goalsKey, &goals)
+			if len(goals) != 2 || goals[0].Currency != USDollar.Code || goals[1].Currency != "" {
+				t.Errorf("goals = %+v, want the spending target in USD and no currency on the drinks target", goals)
+			}
+		},
+	}
+
+	for _, m := range migrations {
+		check, ok := checks[m.Version]
+		if !ok {
+			t.Fatalf("migration %d has no test", m.Version)
+		}
+		t.Run(fmt.Sprintf("v%d", m.Version), func(t *testing.T) {
+			if err := db.Update(m.Apply); err != nil {
+				t.Fatalf("migration %d: %v", m.Version, err)
+			}
+			check(t)
+		})
+	}
+}
+
+func TestMigrateBacksUpAndSetsVersion(t *testing.T) {
+	path := writeV0Fixture(t)
+	db := openFixture(t, path)
+
+	applied, err := Migrate(db, path, false)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("applied %d migrations, want %d", len(applied), len(migrations))
+	}
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		version, err := getSchemaVersion(tx)
+		if version != CurrentSchemaVersion() {
+			t.Errorf("schema version = %d, want %d", version, CurrentSchemaVersion())
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path + ".v0.bak"); err != nil {
+		t.Errorf("no backup of the v0 file: %v", err)
+	}
+
+	if applied, err := Migrate(db, path, false); err != nil || len(applied) != 0 {
+		t.Errorf("second Migrate applied %d migrations, %v; want none", len(applied), err)
+	}
+}
+
+func TestDryRunMigrationsLeavesFile(t *testing.T) {
+	path := writeV0Fixture(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := DryRunMigrations(path)
+	if err != nil {
+		t.Fatalf("DryRunMigrations: %v", err)
+	}
+	if len(pending) != len(migrations) {
+		t.Errorf("%d migrations pending, want %d", len(pending), len(migrations))
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := openFixture(t, path)
+	entries, _ := readFixture(t, db)
+	if entries["Beer"].ID != "" || entries["Beer"].LegacyCost != 6.5 {
+		t.Errorf("the dry run changed the entries: %+v", entries["Beer"])
+	}
+	if len(after) != len(before) {
+		t.Errorf("the file grew from %d to %d bytes", len(before), len(after))
+	}
+	if _, err := os.Stat(path + ".v0.bak"); !os.IsNotExist(err) {
+		t.Errorf("the dry run wrote a backup")
+	}
+}
+
+func TestDryRunMigrationsMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDBFile)
+	if _, err := DryRunMigrations(path); !os.IsNotExist(err) {
+		t.Errorf("DryRunMigrations of a missing file: err = %v, want it to not exist", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("the dry run created the file")
+	}
+}
+
+func TestInitDBMigratesFixture(t *testing.T) {
+	s, err := InitDB(writeV0Fixture(t))
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	defer s.Close()
+
+	entries, err := GetEntriesByDateList(s, 2023, 6, 10)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("GetEntriesByDateList = %v, %v; want the two fixture entries", entries, err)
+	}
+	for _, entry := range entries {
+		if _, err := s.GetEntry(2023, 6, 10, entry.ID); err != nil {
+			t.Errorf("GetEntry(%s): %v", entry.ID, err)
+		}
+	}
+}
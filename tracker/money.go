@@ -0,0 +1,341 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency; amounts are stored in its minor units
+type Currency struct {
+	Code     string `json:"code"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"` // Digits after the decimal point, e.g. 2 for cents
+}
+
+// USDollar is the default currency until the user picks one
+var USDollar = Currency{Code: "USD", Symbol: "$", Decimals: 2}
+
+// Currencies lists the supported currencies
+var Currencies = []Currency{
+	USDollar,
+	{Code: "EUR", Symbol: "€", Decimals: 2},
+	{Code: "GBP", Symbol: "£", Decimals: 2},
+	{Code: "CAD", Symbol: "CA$", Decimals: 2},
+	{Code: "AUD", Symbol: "A$", Decimals: 2},
+	{Code: "NZD", Symbol: "NZ$", Decimals: 2},
+	{Code: "CHF", Symbol: "CHF", Decimals: 2},
+	{Code: "SEK", Symbol: "kr", Decimals: 2},
+	{Code: "NOK", Symbol: "kr", Decimals: 2},
+	{Code: "DKK", Symbol: "kr", Decimals: 2},
+	{Code: "PLN", Symbol: "zł", Decimals: 2},
+	{Code: "CZK", Symbol: "Kč", Decimals: 2},
+	{Code: "MXN", Symbol: "MX$", Decimals: 2},
+	{Code: "BRL", Symbol: "R$", Decimals: 2},
+	{Code: "INR", Symbol: "₹", Decimals: 2},
+	{Code: "CNY", Symbol: "¥", Decimals: 2},
+	{Code: "JPY", Symbol: "¥", Decimals: 0},
+	{Code: "KRW", Symbol: "₩", Decimals: 0},
+}
+
+// LookupCurrency finds a currency by code
+func LookupCurrency(code string) (Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, currency := range Currencies {
+		if currency.Code == code {
+			return currency, nil
+		}
+	}
+	return Currency{}, fmt.Errorf("unknown currency '%s'", code)
+}
+
+// ToMinor converts an amount in major units (e.g. dollars) to minor units (e.g. cents)
+func (c Currency) ToMinor(amount float64) int64 {
+	return int64(math.Round(amount * math.Pow10(c.Decimals)))
+}
+
+// FromMinor converts an amount in minor units to major units
+func (c Currency) FromMinor(minor int64) float64 {
+	return float64(minor) / math.Pow10(c.Decimals)
+}
+
+// Format renders an amount in minor units, e.g. "12.50 EUR"
+func (c Currency) Format(minor int64) string {
+	return fmt.Sprintf("%.*f %s", c.Decimals, c.FromMinor(minor), c.Code)
+}
+
+// formatCost renders an entry's cost in its currency
+func formatCost(entry DayData) string {
+	currency, err := LookupCurrency(entry.Currency)
+	if err != nil {
+		return fmt.Sprintf("%d %s", entry.Cost, entry.Currency)
+	}
+	return currency.Format(entry.Cost)
+}
+
+// Money is an amount in the minor units of a currency
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// GetDefaultCurrency returns the currency new entries are logged in, defaulting to USDollar
+func GetDefaultCurrency(s Store) (Currency, error) {
+	code := USDollar.Code
+	if _, err := loadSetting(s, defaultCurrencyKey, &code); err != nil {
+		return USDollar, err
+	}
+	return LookupCurrency(code)
+}
+
+// SetDefaultCurrency selects the currency new entries are logged in
+func SetDefaultCurrency(s Store, code string) error {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return err
+	}
+	return saveSetting(s, defaultCurrencyKey, currency.Code)
+}
+
+// GetReportingCurrency returns the currency spend is totalled in, defaulting to
+// the default currency
+func GetReportingCurrency(s Store) (Currency, error) {
+	code := ""
+	if _, err := loadSetting(s, reportingCurrencyKey, &code); err != nil || code == "" {
+		currency, defaultErr := GetDefaultCurrency(s)
+		if err == nil {
+			err = defaultErr
+		}
+		return currency, err
+	}
+	return LookupCurrency(code)
+}
+
+// SetReportingCurrency selects the currency spend is totalled in; "" follows the default currency
+func SetReportingCurrency(s Store, code string) error {
+	if code == "" {
+		return saveSetting(s, reportingCurrencyKey, code)
+	}
+
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return err
+	}
+	return saveSetting(s, reportingCurrencyKey, currency.Code)
+}
+
+// stampCurrency logs an entry without a currency in the default currency and
+// normalises the code of one that has it
+func stampCurrency(s Store, data *DayData) error {
+	if data.Currency == "" {
+		currency, err := GetDefaultCurrency(s)
+		if err != nil {
+			return err
+		}
+		data.Currency = currency.Code
+		return nil
+	}
+
+	currency, err := LookupCurrency(data.Currency)
+	if err != nil {
+		return err
+	}
+	data.Currency = currency.Code
+	return nil
+}
+
+// ExchangeRate says one unit of From is worth Rate units of To
+type ExchangeRate struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Rate float64 `json:"rate"`
+}
+
+// GetExchangeRates returns the user's exchange-rate table, sorted by currency pair
+func GetExchangeRates(s Store) ([]ExchangeRate, error) {
+	rates := []ExchangeRate{}
+	_, err := loadSetting(s, exchangeRatesKey, &rates)
+	return rates, err
+}
+
+// SetExchangeRate adds or replaces the rate from one currency to another. The
+// reverse direction is derived from it, so only one of the pair needs to be set.
+func SetExchangeRate(s Store, from, to string, rate float64) error {
+	fromCurrency, err := LookupCurrency(from)
+	if err != nil {
+		return err
+	}
+	toCurrency, err := LookupCurrency(to)
+	if err != nil {
+		return err
+	}
+	if fromCurrency.Code == toCurrency.Code {
+		return errors.New("an exchange rate needs two different currencies")
+	}
+	if rate <= 0 {
+		return errors.New("exchange rate must be greater than 0")
+	}
+
+	rates := []ExchangeRate{}
+	return updateSetting(s, exchangeRatesKey, &rates, func() error {
+		// Drop the pair in either direction so the table holds one rate per pair
+		kept := []ExchangeRate{}
+		for _, r := range rates {
+			if !r.connects(fromCurrency.Code, toCurrency.Code) {
+				kept = append(kept, r)
+			}
+		}
+		kept = append(kept, ExchangeRate{From: fromCurrency.Code, To: toCurrency.Code, Rate: rate})
+		sort.Slice(kept, func(i, j int) bool {
+			if kept[i].From != kept[j].From {
+				return kept[i].From < kept[j].From
+			}
+			return kept[i].To < kept[j].To
+		})
+		rates = kept
+		return nil
+	})
+}
+
+// DeleteExchangeRate removes the rate between two currencies, in either direction
+func DeleteExchangeRate(s Store, from, to string) error {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	rates := []ExchangeRate{}
+	return updateSetting(s, exchangeRatesKey, &rates, func() error {
+		kept := []ExchangeRate{}
+		for _, r := range rates {
+			if !r.connects(from, to) {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == len(rates) {
+			return fmt.Errorf("no exchange rate between %s and %s", from, to)
+		}
+		rates = kept
+		return nil
+	})
+}
+
+// connects reports whether r is the rate between a and b, in either direction
+func (r ExchangeRate) connects(a, b string) bool {
+	return (r.From == a && r.To == b) || (r.From == b && r.To == a)
+}
+
+// rateTable converts between currencies using the user's exchange rates
+type rateTable []ExchangeRate
+
+// rate returns how many units of to one unit of from is worth, using a direct
+// rate, the inverse of one, or a path through a single intermediate currency
+func (t rateTable) rate(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	if rate, ok := t.direct(from, to); ok {
+		return rate, true
+	}
+
+	for _, r := range t {
+		for _, via := range []string{r.From, r.To} {
+			if via == from || via == to {
+				continue
+			}
+			first, ok := t.direct(from, via)
+			if !ok {
+				continue
+			}
+			if second, ok := t.direct(via, to); ok {
+				return first * second, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// direct looks up the rate stored for a pair in either direction
+func (t rateTable) direct(from, to string) (float64, bool) {
+	for _, r := range t {
+		if r.From == from && r.To == to {
+			return r.Rate, true
+		}
+		if r.From == to && r.To == from {
+			return 1 / r.Rate, true
+		}
+	}
+	return 0, false
+}
+
+// convert turns an amount into minor units of to
+func (t rateTable) convert(amount Money, to Currency) (int64, error) {
+	from, err := LookupCurrency(amount.Currency)
+	if err != nil {
+		return 0, err
+	}
+	if from.Code == to.Code {
+		return amount.Amount, nil
+	}
+
+	rate, ok := t.rate(from.Code, to.Code)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate from %s to %s", from.Code, to.Code)
+	}
+	return to.ToMinor(from.FromMinor(amount.Amount) * rate), nil
+}
+
+// ConvertMoney converts an amount to another currency with the user's exchange rates
+func ConvertMoney(s Store, amount Money, to string) (Money, error) {
+	currency, err := LookupCurrency(to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	rates, err := GetExchangeRates(s)
+	if err != nil {
+		return Money{}, err
+	}
+
+	converted, err := rateTable(rates).convert(amount, currency)
+	return Money{Amount: converted, Currency: currency.Code}, err
+}
+
+// moneyTotal sums amounts in a reporting currency, keeping track of the
+// currencies that could not be converted for lack of a rate
+type moneyTotal struct {
+	currency Currency
+	rates    rateTable
+	missing  map[string]bool
+}
+
+// newMoneyTotal prepares to total amounts in the reporting currency
+func newMoneyTotal(s Store) (*moneyTotal, error) {
+	currency, err := GetReportingCurrency(s)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := GetExchangeRates(s)
+	if err != nil {
+		return nil, err
+	}
+	return &moneyTotal{currency: currency, rates: rates, missing: map[string]bool{}}, nil
+}
+
+// entryCost returns an entry's cost in the reporting currency, or 0 if it can't be converted
+func (m *moneyTotal) entryCost(entry DayData) int64 {
+	if entry.Cost == 0 {
+		return 0
+	}
+
+	amount, err := m.rates.convert(Money{Amount: entry.Cost, Currency: entry.Currency}, m.currency)
+	if err != nil {
+		m.missing[entry.Currency] = true
+		return 0
+	}
+	return amount
+}
+
+// missingRates lists the currencies left out of the totals
+func (m *moneyTotal) missingRates() []string {
+	return sortedKeys(m.missing)
+}
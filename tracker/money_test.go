@@ -0,0 +1,129 @@
+package tracker
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCurrencyMinorUnits(t *testing.T) {
+	tests := []struct {
+		code   string
+		amount float64
+		minor  int64
+		format string
+	}{
+		{"USD", 6.5, 650, "6.50 USD"},
+		{"EUR", 0.1 + 0.2, 30, "0.30 EUR"},
+		{"JPY", 1200, 1200, "1200 JPY"},
+		{"KRW", 4500.4, 4500, "4500 KRW"},
+	}
+
+	for _, tt := range tests {
+		currency, err := LookupCurrency(tt.code)
+		if err != nil {
+			t.Fatalf("LookupCurrency(%s): %v", tt.code, err)
+		}
+		if got := currency.ToMinor(tt.amount); got != tt.minor {
+			t.Errorf("%s.ToMinor(%g) = %d, want %d", tt.code, tt.amount, got, tt.minor)
+		}
+		if got := currency.Format(tt.minor); got != tt.format {
+			t.Errorf("%s.Format(%d) = %q, want %q", tt.code, tt.minor, got, tt.format)
+		}
+		if got := currency.ToMinor(currency.FromMinor(tt.minor)); got != tt.minor {
+			t.Errorf("%s: %d minor units came back as %d", tt.code, tt.minor, got)
+		}
+	}
+
+	if _, err := LookupCurrency(" eur "); err != nil {
+		t.Errorf("LookupCurrency ignores case and spaces: %v", err)
+	}
+	if _, err := LookupCurrency("XYZ"); err == nil {
+		t.Error("LookupCurrency accepted an unknown code")
+	}
+}
+
+func TestConvertMoney(t *testing.T) {
+	s := NewMemoryStore()
+	if err := SetExchangeRate(s, "EUR", "USD", 1.25); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetExchangeRate(s, "USD", "JPY", 150); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		from Money
+		to   string
+		want int64
+	}{
+		{"same currency", Money{Amount: 999, Currency: "EUR"}, "EUR", 999},
+		{"direct", Money{Amount: 1000, Currency: "EUR"}, "USD", 1250},
+		{"inverse", Money{Amount: 1250, Currency: "USD"}, "EUR", 1000},
+		{"through USD", Money{Amount: 1000, Currency: "EUR"}, "JPY", 1875},
+		{"to fewer decimals", Money{Amount: 199, Currency: "USD"}, "JPY", 299},
+	}
+	for _, tt := range tests {
+		got, err := ConvertMoney(s, tt.from, tt.to)
+		if err != nil || got.Amount != tt.want || got.Currency != tt.to {
+			t.Errorf("%s: ConvertMoney(%+v, %s) = %+v, %v; want %d %s", tt.name, tt.from, tt.to, got, err, tt.want, tt.to)
+		}
+	}
+
+	if _, err := ConvertMoney(s, Money{Amount: 100, Currency: "GBP"}, "USD"); err == nil {
+		t.Error("converting without a rate succeeded")
+	}
+}
+
+func TestSetExchangeRateReplacesPair(t *testing.T) {
+	s := NewMemoryStore()
+	if err := SetExchangeRate(s, "EUR", "USD", 1.25); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetExchangeRate(s, "usd", "eur", 0.5); err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := GetExchangeRates(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[0] != (ExchangeRate{From: "USD", To: "EUR", Rate: 0.5}) {
+		t.Errorf("rates = %+v, want only USD→EUR at 0.5", rates)
+	}
+
+	if err := SetExchangeRate(s, "USD", "USD", 1); err == nil {
+		t.Error("a rate from a currency to itself was accepted")
+	}
+	if err := SetExchangeRate(s, "USD", "GBP", 0); err == nil {
+		t.Error("a zero rate was accepted")
+	}
+
+	if err := DeleteExchangeRate(s, "EUR", "USD"); err != nil {
+		t.Fatalf("DeleteExchangeRate: %v", err)
+	}
+	if err := DeleteExchangeRate(s, "EUR", "USD"); err == nil {
+		t.Error("deleting a missing rate succeeded")
+	}
+}
+
+func TestSetExchangeRatesConcurrently(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		var wg sync.WaitGroup
+		for _, currency := range Currencies[1:] {
+			wg.Add(1)
+			go func(code string) {
+				defer wg.Done()
+				if err := SetExchangeRate(s, USDollar.Code, code, 2); err != nil {
+					t.Error(err)
+				}
+			}(currency.Code)
+		}
+		wg.Wait()
+
+		rates, err := GetExchangeRates(s)
+		if err != nil || len(rates) != len(Currencies)-1 {
+			t.Errorf("%d rates saved, %v; want %d", len(rates), err, len(Currencies)-1)
+		}
+	})
+}
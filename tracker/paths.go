@@ -0,0 +1,172 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// appDirName is the directory created under the user's data and config directories
+	appDirName = "AlcoholTracker"
+
+	// DBPathEnv overrides the database location when set
+	DBPathEnv = "ALCOHOLTRACKER_DB"
+
+	configFileName = "config.json"
+)
+
+// fileConfig is the optional config file at <user config dir>/AlcoholTracker/config.json
+type fileConfig struct {
+	Database string `json:"database"`
+}
+
+// DataDir returns the per-user directory holding tracker data:
+// $XDG_DATA_HOME (or ~/.local/share) on Linux, ~/Library/Application Support
+// on macOS and %AppData% on Windows.
+func DataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, appDirName), nil
+	default:
+		if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+			return filepath.Join(dir, appDirName), nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share", appDirName), nil
+	}
+}
+
+// ConfigFilePath returns the location of the optional config file
+func ConfigFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, configFileName), nil
+}
+
+// readConfigFile loads the config file, returning an empty config if it does not exist
+func readConfigFile() (fileConfig, error) {
+	var cfg fileConfig
+
+	path, err := ConfigFilePath()
+	if err != nil {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// ResolveDBPath picks the database file. The first non-empty source wins:
+// flagPath, the ALCOHOLTRACKER_DB environment variable, the "database" key of
+// the config file, and finally tracker.db inside DataDir.
+func ResolveDBPath(flagPath string) (string, error) {
+	if flagPath != "" {
+		return flagPath, nil
+	}
+
+	if envPath := os.Getenv(DBPathEnv); envPath != "" {
+		return envPath, nil
+	}
+
+	cfg, err := readConfigFile()
+	if err != nil {
+		return "", err
+	}
+	if cfg.Database != "" {
+		return cfg.Database, nil
+	}
+
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, DefaultDBFile), nil
+}
+
+// MoveLegacyDB moves a tracker.db left in the working directory by older
+// versions to path. It only acts once: when path does not exist yet and the
+// legacy file does. It returns the path of the file it moved, or "" if it
+// moved nothing.
+func MoveLegacyDB(path string) (string, error) {
+	legacy, err := filepath.Abs(DefaultDBFile)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if legacy == target {
+		return "", nil
+	}
+
+	if _, err := os.Stat(target); !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return "", err
+	}
+
+	// Rename fails across filesystems, so fall back to copy and remove
+	if err := os.Rename(legacy, target); err != nil {
+		if err := copyFile(legacy, target); err != nil {
+			return "", err
+		}
+		if err := os.Remove(legacy); err != nil {
+			return legacy, err
+		}
+	}
+	return legacy, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,123 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Settings keys
+const (
+	standardDrinkKey = "standard_drink"
+	displayUnitKey   = "display_unit"
+	tiersKey         = "tiers"
+	bodyProfileKey   = "body_profile"
+	dayRolloverKey   = "day_rollover_hour"
+	timezoneKey      = "timezone"
+	goalsKey         = "goals"
+	goalHistoryKey   = "goal_history"
+	monthlyBudgetKey = "monthly_budget"
+
+	defaultCurrencyKey   = "default_currency"
+	reportingCurrencyKey = "reporting_currency"
+	exchangeRatesKey     = "exchange_rates"
+	snapshotPolicyKey    = "snapshot_policy"
+	apiTokenKey          = "api_token"
+)
+
+// localSettings belong to this install rather than its data, so backups leave
+// them out and restores keep them, even in replace mode
+var localSettings = map[string]bool{apiTokenKey: true}
+
+// loadSetting decodes the JSON value under key into v, reporting whether it was set
+func loadSetting(s Store, key string, v interface{}) (bool, error) {
+	value, err := s.GetSetting(key)
+	if err != nil || value == nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(value, v); err != nil {
+		return false, fmt.Errorf("invalid setting %s: %v", key, err)
+	}
+	return true, nil
+}
+
+// saveSetting stores v as JSON under key
+func saveSetting(s Store, key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.PutSetting(key, value)
+}
+
+// updateSetting atomically decodes the JSON value under key into v, calls update
+// to change it and stores the result, so concurrent changes to the same setting
+// aren't lost. update must not use the store.
+func updateSetting(s Store, key string, v interface{}, update func() error) error {
+	return s.UpdateSetting(key, func(value []byte) ([]byte, error) {
+		if value != nil {
+			if err := json.Unmarshal(value, v); err != nil {
+				return nil, fmt.Errorf("invalid setting %s: %v", key, err)
+			}
+		}
+		if err := update(); err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	})
+}
+
+// StandardDrink is a national definition of one standard drink in grams of pure alcohol
+type StandardDrink struct {
+	Code  string  `json:"code"`
+	Name  string  `json:"name"`
+	Grams float64 `json:"grams"`
+}
+
+// CustomStandardDrinkCode selects a user-supplied number of grams
+const CustomStandardDrinkCode = "CUSTOM"
+
+// USStandardDrink is the default definition (17.7 mL of ethanol)
+var USStandardDrink = StandardDrink{Code: "US", Name: "US standard drink", Grams: 14}
+
+// StandardDrinks lists the built-in definitions
+var StandardDrinks = []StandardDrink{
+	USStandardDrink,
+	{Code: "UK", Name: "UK unit", Grams: 8},
+	{Code: "AU", Name: "Australian standard drink", Grams: 10},
+	{Code: "CA", Name: "Canadian standard drink", Grams: 13.45},
+	{Code: "EU10", Name: "10 g standard drink (EU/WHO)", Grams: 10},
+}
+
+// GetStandardDrink returns the selected definition, defaulting to USStandardDrink
+func GetStandardDrink(s Store) (StandardDrink, error) {
+	def := USStandardDrink
+	if _, err := loadSetting(s, standardDrinkKey, &def); err != nil {
+		return USStandardDrink, err
+	}
+	return def, nil
+}
+
+// SetStandardDrink selects a built-in definition by code, or CustomStandardDrinkCode
+// with customGrams grams of alcohol per drink
+func SetStandardDrink(s Store, code string, customGrams float64) error {
+	if code == CustomStandardDrinkCode {
+		if customGrams <= 0 {
+			return errors.New("custom standard drink must be more than 0 grams")
+		}
+		def := StandardDrink{
+			Code:  CustomStandardDrinkCode,
+			Name:  fmt.Sprintf("Custom (%g g)", customGrams),
+			Grams: customGrams,
+		}
+		return saveSetting(s, standardDrinkKey, def)
+	}
+
+	for _, def := range StandardDrinks {
+		if def.Code == code {
+			return saveSetting(s, standardDrinkKey, def)
+		}
+	}
+	return fmt.Errorf("unknown standard drink definition '%s'", code)
+}
@@ -0,0 +1,62 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStandardDrinkDefinitions(t *testing.T) {
+	// A 500 mL beer at 5% holds 19.725 g of alcohol
+	tests := []struct {
+		code        string
+		customGrams float64
+		grams       float64
+		drinks      float64
+	}{
+		{"US", 0, 14, 1.408929},
+		{"UK", 0, 8, 2.465625},
+		{"AU", 0, 10, 1.9725},
+		{"CA", 0, 13.45, 1.466543},
+		{"EU10", 0, 10, 1.9725},
+		{CustomStandardDrinkCode, 12, 12, 1.64375},
+		{CustomStandardDrinkCode, 0.5, 0.5, 39.45},
+	}
+
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+		mustAdd(t, s, Date{2024, 3, 5}, DayData{Alcohol: "Beer", Quantity: 500, ConsumedAt: time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)})
+
+		if def, err := GetStandardDrink(s); err != nil || def != USStandardDrink {
+			t.Errorf("the default definition is %+v, %v; want %+v", def, err, USStandardDrink)
+		}
+		for _, test := range tests {
+			if err := SetStandardDrink(s, test.code, test.customGrams); err != nil {
+				t.Fatalf("SetStandardDrink(%s, %g): %v", test.code, test.customGrams, err)
+			}
+			def, err := GetStandardDrink(s)
+			if err != nil || def.Code != test.code || def.Grams != test.grams {
+				t.Errorf("%s: the definition is %+v, %v; want %g g", test.code, def, err, test.grams)
+			}
+			drinks, err := GetTotalDrinksOnDay(s, 2024, 3, 5)
+			if err != nil || math.Abs(drinks-test.drinks) > 1e-6 {
+				t.Errorf("%s at %g g: %g drinks, %v; want %g", test.code, test.grams, drinks, err, test.drinks)
+			}
+		}
+
+		// Rejected definitions leave the last one selected
+		for _, bad := range []struct {
+			code        string
+			customGrams float64
+		}{{"XX", 0}, {"us", 0}, {CustomStandardDrinkCode, 0}, {CustomStandardDrinkCode, -8}} {
+			if err := SetStandardDrink(s, bad.code, bad.customGrams); err == nil {
+				t.Errorf("SetStandardDrink(%s, %g) was accepted", bad.code, bad.customGrams)
+			}
+		}
+		if def, err := GetStandardDrink(s); err != nil || def.Grams != 0.5 {
+			t.Errorf("after rejected changes the definition is %+v, %v; want the 0.5 g custom one", def, err)
+		}
+	})
+}
@@ -0,0 +1,396 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Snapshot is a copy of the database file taken while it was in use
+type Snapshot struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	TakenAt time.Time `json:"taken_at"`
+	Size    int64     `json:"size"` // Bytes
+}
+
+// SnapshotPolicy controls how often snapshots are taken and how many are kept
+type SnapshotPolicy struct {
+	IntervalHours int `json:"interval_hours"` // Hours between scheduled snapshots; 0 only snapshots on startup
+	Daily         int `json:"daily"`          // Days to keep the newest snapshot of
+	Weekly        int `json:"weekly"`         // Weeks to keep the newest snapshot of, beyond the daily ones
+}
+
+// Used until the user saves their own policy
+var defaultSnapshotPolicy = SnapshotPolicy{IntervalHours: 6, Daily: 7, Weekly: 4}
+
+const (
+	snapshotPrefix     = "tracker-"
+	snapshotSuffix     = ".db"
+	snapshotTimeLayout = "20060102-150405.000"
+)
+
+// SnapshotDir is the directory snapshots of the database at dbPath are kept in
+func SnapshotDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "backups")
+}
+
+// GetSnapshotPolicy returns the saved policy, or the default if none was saved
+func GetSnapshotPolicy(s Store) (SnapshotPolicy, error) {
+	policy := defaultSnapshotPolicy
+	_, err := loadSetting(s, snapshotPolicyKey, &policy)
+	return policy, err
+}
+
+// SaveSnapshotPolicy validates and stores a policy. A new interval takes effect from the next start.
+func SaveSnapshotPolicy(s Store, policy SnapshotPolicy) error {
+	if policy.IntervalHours < 0 {
+		return errors.New("snapshot interval must not be negative")
+	}
+	if policy.Daily < 1 {
+		return errors.New("at least one daily snapshot must be kept")
+	}
+	if policy.Weekly < 0 {
+		return errors.New("weekly snapshots to keep must not be negative")
+	}
+	return saveSetting(s, snapshotPolicyKey, policy)
+}
+
+// TakeSnapshot writes a consistent copy of the open database into dir, named for
+// takenAt. The copy is made inside a read transaction with Tx.WriteTo, so writers
+// are not blocked, and only appears under its final name once it is complete.
+func TakeSnapshot(s *BoltStore, dir string, takenAt time.Time) (Snapshot, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Snapshot{}, err
+	}
+
+	takenAt = takenAt.In(time.Local)
+	name := snapshotPrefix + takenAt.Format(snapshotTimeLayout) + snapshotSuffix
+	path := filepath.Join(dir, name)
+
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	var size int64
+	err = s.db.View(func(tx *bbolt.Tx) error {
+		size, err = tx.WriteTo(tmp)
+		return err
+	})
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Name: name, Path: path, TakenAt: takenAt, Size: size}, nil
+}
+
+// ListSnapshots returns the snapshots in dir, newest first
+func ListSnapshots(dir string) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return snapshots, nil
+	}
+	if err != nil {
+		return snapshots, err
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix)
+		takenAt, err := time.ParseInLocation(snapshotTimeLayout, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return snapshots, err
+		}
+		snapshots = append(snapshots, Snapshot{Name: name, Path: filepath.Join(dir, name), TakenAt: takenAt, Size: info.Size()})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].TakenAt.After(snapshots[j].TakenAt)
+	})
+	return snapshots, nil
+}
+
+// PruneSnapshots deletes the snapshots in dir that the policy doesn't keep: the
+// newest one on each of the last policy.Daily days with snapshots, and the newest
+// one in each of the policy.Weekly weeks before those. The newest snapshot is always kept.
+func PruneSnapshots(dir string, policy SnapshotPolicy) ([]Snapshot, error) {
+	removed := []Snapshot{}
+
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		return removed, err
+	}
+
+	days := map[string]bool{}
+	weeks := map[string]bool{}
+	weekly := 0
+	for i, snapshot := range snapshots {
+		day := snapshot.TakenAt.Format("2006-01-02")
+		year, week := snapshot.TakenAt.ISOWeek()
+		weekKey := fmt.Sprintf("%d-W%02d", year, week)
+
+		// Snapshots are newest first, so the first one seen for a day or week is its newest
+		keep := i == 0
+		switch {
+		case days[day]:
+		case len(days) < policy.Daily:
+			days[day] = true
+			weeks[weekKey] = true
+			keep = true
+		case !weeks[weekKey] && weekly < policy.Weekly:
+			weeks[weekKey] = true
+			weekly++
+			keep = true
+		}
+
+		if keep {
+			continue
+		}
+		if err := os.Remove(snapshot.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, snapshot)
+	}
+	return removed, nil
+}
+
+// RestoreSnapshot replaces the contents of the open database with those of a
+// snapshot file in a single transaction, keeping the local settings, then migrates
+// them to the current schema if the snapshot is older. The snapshot is checked for
+// corruption first.
+func (s *BoltStore) RestoreSnapshot(path string) error {
+	// bbolt would create a missing file
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot open snapshot: %v", err)
+	}
+
+	snapshot, err := bbolt.Open(path, 0600, &bbolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("cannot open snapshot: %v", err)
+	}
+	defer snapshot.Close()
+
+	err = snapshot.View(func(source *bbolt.Tx) error {
+		// Drain every error so the checker finishes before the transaction closes
+		var corrupt error
+		for err := range source.Check() {
+			if corrupt == nil {
+				corrupt = fmt.Errorf("snapshot is corrupt: %v", err)
+			}
+		}
+		if corrupt != nil {
+			return corrupt
+		}
+
+		version, err := getSchemaVersion(source)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot schema version: %v", err)
+		}
+		if version > CurrentSchemaVersion() {
+			return fmt.Errorf("snapshot schema version %d is newer than supported version %d", version, CurrentSchemaVersion())
+		}
+
+		return s.db.Update(func(tx *bbolt.Tx) error {
+			// Local settings such as the API token belong to this install, not the snapshot
+			local := map[string][]byte{}
+			if bucket := tx.Bucket(settingsBucket); bucket != nil {
+				for key := range localSettings {
+					if value := bucket.Get([]byte(key)); value != nil {
+						local[key] = append([]byte(nil), value...)
+					}
+				}
+			}
+
+			names := [][]byte{}
+			err := tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+				names = append(names, append([]byte(nil), name...))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+
+			err = source.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+				copied, err := tx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(copied, bucket)
+			})
+			if err != nil || len(local) == 0 {
+				return err
+			}
+
+			settings, err := tx.CreateBucketIfNotExists(settingsBucket)
+			if err != nil {
+				return err
+			}
+			for key, value := range local {
+				if err := settings.Put([]byte(key), value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = Migrate(s.db, s.path, false)
+	return err
+}
+
+// copyBucket copies every key and nested bucket of src into dst
+func copyBucket(dst, src *bbolt.Bucket) error {
+	return src.ForEach(func(key, value []byte) error {
+		// A nil value marks a nested bucket
+		if value != nil {
+			return dst.Put(key, value)
+		}
+
+		nested, err := dst.CreateBucket(key)
+		if err != nil {
+			return err
+		}
+		return copyBucket(nested, src.Bucket(key))
+	})
+}
+
+// Snapshotter takes, prunes and restores snapshots of a BoltStore
+type Snapshotter struct {
+	mu    sync.Mutex
+	store *BoltStore
+	dir   string
+	now   func() time.Time                     // Replaced in tests
+	after func(time.Duration) <-chan time.Time // Replaced in tests
+}
+
+// NewSnapshotter keeps snapshots of store in SnapshotDir next to its file
+func NewSnapshotter(store *BoltStore) *Snapshotter {
+	return &Snapshotter{store: store, dir: SnapshotDir(store.path), now: time.Now, after: time.After}
+}
+
+// Dir is the directory the snapshots are kept in
+func (sn *Snapshotter) Dir() string {
+	return sn.dir
+}
+
+// Snapshot takes a snapshot now and prunes the old ones
+func (sn *Snapshotter) Snapshot() (Snapshot, error) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	return sn.snapshot()
+}
+
+func (sn *Snapshotter) snapshot() (Snapshot, error) {
+	policy, err := GetSnapshotPolicy(sn.store)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot, err := TakeSnapshot(sn.store, sn.dir, sn.now())
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	_, err = PruneSnapshots(sn.dir, policy)
+	return snapshot, err
+}
+
+// List returns the kept snapshots, newest first
+func (sn *Snapshotter) List() ([]Snapshot, error) {
+	return ListSnapshots(sn.dir)
+}
+
+// Restore loads the named snapshot into the database. The current contents are
+// snapshotted first, so a restore can itself be undone.
+func (sn *Snapshotter) Restore(name string) error {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	snapshots, err := ListSnapshots(sn.dir)
+	if err != nil {
+		return err
+	}
+
+	// Only names from the list are accepted, so nothing outside dir can be opened
+	for _, snapshot := range snapshots {
+		if snapshot.Name != name {
+			continue
+		}
+		// Not pruned yet, as that could delete the snapshot being restored
+		if _, err := TakeSnapshot(sn.store, sn.dir, sn.now()); err != nil {
+			return fmt.Errorf("failed to snapshot the current database: %v", err)
+		}
+		return sn.store.RestoreSnapshot(snapshot.Path)
+	}
+	return fmt.Errorf("no snapshot named %s", name)
+}
+
+// Run takes a snapshot straight away and then on the policy's interval until ctx
+// is cancelled. Failures are logged rather than stopping the schedule.
+func (sn *Snapshotter) Run(ctx context.Context) {
+	if snapshot, err := sn.Snapshot(); err != nil {
+		log.Println("Failed to take snapshot:", err)
+	} else {
+		log.Println("Snapshot saved to", snapshot.Path)
+	}
+
+	policy, err := GetSnapshotPolicy(sn.store)
+	if err != nil {
+		log.Println("Failed to read snapshot policy:", err)
+		return
+	}
+	if policy.IntervalHours == 0 {
+		return
+	}
+
+	interval := time.Duration(policy.IntervalHours) * time.Hour
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sn.after(interval):
+			if _, err := sn.Snapshot(); err != nil {
+				log.Println("Failed to take snapshot:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package tracker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestoreSnapshotKeepsLocalSettings(t *testing.T) {
+	s := newTestBoltStore(t)
+	entry := mustAdd(t, s, Date{Year: 2024, Month: 3, Day: 5}, DayData{Alcohol: "Beer", Quantity: 500})
+	if _, err := GetAPIToken(s); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := TakeSnapshot(s, t.TempDir(), time.Now())
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+
+	later := mustAdd(t, s, Date{Year: 2024, Month: 3, Day: 6}, DayData{Alcohol: "Wine", Quantity: 150})
+	token, err := ResetAPIToken(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RestoreSnapshot(snapshot.Path); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if _, err := s.GetEntry(2024, 3, 5, entry.ID); err != nil {
+		t.Errorf("the snapshotted entry is gone: %v", err)
+	}
+	if _, err := s.GetEntry(2024, 3, 6, later.ID); err == nil {
+		t.Error("an entry added after the snapshot survived the restore")
+	}
+	if restored, err := GetAPIToken(s); err != nil || restored != token {
+		t.Errorf("after the restore the token is %q, %v; want the current %q", restored, err, token)
+	}
+}
+
+// snapshotNames lists the names of the snapshots in dir, newest first
+func snapshotNames(t *testing.T, dir string) string {
+	t.Helper()
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	names := []string{}
+	for _, snapshot := range snapshots {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(snapshot.Name, snapshotPrefix), snapshotSuffix))
+	}
+	return strings.Join(names, " ")
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	taken := []time.Time{
+		time.Date(2024, 3, 20, 18, 0, 0, 0, time.Local), // Day 1, week 12
+		time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 20, 6, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 19, 12, 0, 0, 0, time.Local), // Day 2
+		time.Date(2024, 3, 17, 12, 0, 0, 0, time.Local), // Day 3, week 11
+		time.Date(2024, 3, 16, 12, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 12, 12, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 8, 12, 0, 0, 0, time.Local), // Week 10
+		time.Date(2024, 3, 5, 12, 0, 0, 0, time.Local),
+		time.Date(2024, 2, 28, 12, 0, 0, 0, time.Local), // Week 9
+		time.Date(2024, 2, 20, 12, 0, 0, 0, time.Local), // Week 8, one week too many
+	}
+	for _, at := range taken {
+		name := snapshotPrefix + at.Format(snapshotTimeLayout) + snapshotSuffix
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Files that aren't snapshots are left alone
+	for _, name := range []string{"notes.txt", snapshotPrefix + "latest" + snapshotSuffix} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := PruneSnapshots(dir, SnapshotPolicy{Daily: 3, Weekly: 2})
+	if err != nil {
+		t.Fatalf("PruneSnapshots: %v", err)
+	}
+	if len(removed) != 6 {
+		t.Errorf("removed %d snapshots, want 6", len(removed))
+	}
+	want := "20240320-180000.000 20240319-120000.000 20240317-120000.000 20240308-120000.000 20240228-120000.000"
+	if got := snapshotNames(t, dir); got != want {
+		t.Errorf("kept %s, want %s", got, want)
+	}
+	for _, name := range []string{"notes.txt", snapshotPrefix + "latest" + snapshotSuffix} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s was removed: %v", name, err)
+		}
+	}
+
+	// Pruning again with the same policy changes nothing
+	if removed, err := PruneSnapshots(dir, SnapshotPolicy{Daily: 3, Weekly: 2}); err != nil || len(removed) != 0 {
+		t.Errorf("pruning again removed %d, %v; want nothing", len(removed), err)
+	}
+	if removed, err := PruneSnapshots(dir, SnapshotPolicy{Daily: 1}); err != nil || len(removed) != 4 {
+		t.Errorf("keeping one day removed %d, %v; want 4", len(removed), err)
+	}
+	if got := snapshotNames(t, dir); got != "20240320-180000.000" {
+		t.Errorf("kept %s, want only the newest", got)
+	}
+}
+
+func TestSnapshotterRun(t *testing.T) {
+	s := newTestBoltStore(t)
+	if err := SaveSnapshotPolicy(s, SnapshotPolicy{IntervalHours: 6, Daily: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The clock is set before each snapshot, and every wait for the interval is reported
+	clock := make(chan time.Time, 1)
+	ticks := make(chan time.Time)
+	waits := make(chan time.Duration)
+	sn := NewSnapshotter(s)
+	sn.now = func() time.Time { return <-clock }
+	sn.after = func(d time.Duration) <-chan time.Time {
+		waits <- d
+		return ticks
+	}
+	tick := func(at time.Time) {
+		t.Helper()
+		clock <- at
+		ticks <- at
+		if d := <-waits; d != 6*time.Hour {
+			t.Fatalf("waited %s between snapshots, want 6h", d)
+		}
+	}
+
+	before := mustAdd(t, s, Date{2024, 3, 20}, DayData{Alcohol: "Beer", Quantity: 500})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	start := time.Date(2024, 3, 20, 8, 0, 0, 0, time.Local)
+	clock <- start
+	go func() {
+		sn.Run(ctx)
+		close(done)
+	}()
+	if d := <-waits; d != 6*time.Hour {
+		t.Fatalf("waited %s after the first snapshot, want 6h", d)
+	}
+	if got := snapshotNames(t, sn.Dir()); got != "20240320-080000.000" {
+		t.Errorf("after starting the snapshots are %s, want one from 08:00", got)
+	}
+
+	// Two days' worth, keeping the newest of each of the last two
+	for at := start.Add(6 * time.Hour); !at.After(time.Date(2024, 3, 22, 2, 0, 0, 0, time.Local)); at = at.Add(6 * time.Hour) {
+		tick(at)
+	}
+	cancel()
+	<-done
+	if got, want := snapshotNames(t, sn.Dir()), "20240322-020000.000 20240321-200000.000"; got != want {
+		t.Errorf("the snapshots are %s, want %s", got, want)
+	}
+
+	// Restoring snapshots what's there first, and isn't pruned away by it
+	after := mustAdd(t, s, Date{2024, 3, 22}, DayData{Alcohol: "Wine", Quantity: 150})
+	clock <- time.Date(2024, 3, 22, 9, 0, 0, 0, time.Local)
+	if err := sn.Restore(snapshotPrefix + "20240321-200000.000" + snapshotSuffix); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := s.GetEntry(2024, 3, 20, before.ID); err != nil {
+		t.Errorf("the snapshotted entry is gone: %v", err)
+	}
+	if _, err := s.GetEntry(2024, 3, 22, after.ID); err == nil {
+		t.Error("an entry added after the snapshot survived the restore")
+	}
+	if got, want := snapshotNames(t, sn.Dir()), "20240322-090000.000 20240322-020000.000 20240321-200000.000"; got != want {
+		t.Errorf("after restoring the snapshots are %s, want %s", got, want)
+	}
+
+	// The restore can itself be undone
+	clock <- time.Date(2024, 3, 22, 9, 5, 0, 0, time.Local)
+	if err := sn.Restore(snapshotPrefix + "20240322-090000.000" + snapshotSuffix); err != nil {
+		t.Fatalf("undoing the restore: %v", err)
+	}
+	if _, err := s.GetEntry(2024, 3, 22, after.ID); err != nil {
+		t.Errorf("undoing the restore didn't bring back the later entry: %v", err)
+	}
+
+	for _, name := range []string{"missing.db", "../" + DefaultDBFile, ""} {
+		if err := sn.Restore(name); err == nil {
+			t.Errorf("Restore(%q) succeeded", name)
+		}
+	}
+}
+
+func TestSnapshotterRunWithoutInterval(t *testing.T) {
+	s := newTestBoltStore(t)
+	if err := SaveSnapshotPolicy(s, SnapshotPolicy{IntervalHours: 0, Daily: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	sn := NewSnapshotter(s)
+	sn.after = func(time.Duration) <-chan time.Time {
+		t.Fatal("waited for an interval with scheduled snapshots turned off")
+		return nil
+	}
+	sn.Run(context.Background()) // Returns after the startup snapshot
+	if snapshots, err := sn.List(); err != nil || len(snapshots) != 1 {
+		t.Errorf("took %d snapshots, %v; want only the one on startup", len(snapshots), err)
+	}
+}
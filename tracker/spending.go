@@ -0,0 +1,221 @@
+package tracker
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// mostExpensiveDays is how many days a spending report ranks
+const mostExpensiveDays = 5
+
+// CategorySpend is the spend on one drink category over a period
+type CategorySpend struct {
+	Category       string  `json:"category"`
+	Spend          int64   `json:"spend"`
+	StandardDrinks float64 `json:"standard_drinks"`
+	CostPerDrink   int64   `json:"cost_per_drink"` // Per standard drink; 0 when nothing was drunk
+}
+
+// DaySpend is the spend on one drinking day
+type DaySpend struct {
+	Date           Date    `json:"date"`
+	Spend          int64   `json:"spend"`
+	StandardDrinks float64 `json:"standard_drinks"`
+}
+
+// SpendingReport breaks down the spend from From through To (inclusive). Amounts
+// are in minor units of the reporting currency.
+type SpendingReport struct {
+	Period         string          `json:"period"` // PeriodWeek, PeriodMonth, PeriodYear or "" for a custom range
+	From           Date            `json:"from"`
+	To             Date            `json:"to"`
+	Currency       string          `json:"currency"`
+	MissingRates   []string        `json:"missing_rates"` // Currencies left out for lack of an exchange rate
+	Total          int64           `json:"total"`
+	StandardDrinks float64         `json:"standard_drinks"`
+	CostPerDrink   int64           `json:"cost_per_drink"` // Per standard drink; 0 when nothing was drunk
+	PerDay         int64           `json:"per_day"`        // Averaged over the days up to the current one
+	Categories     []CategorySpend `json:"categories"`     // Most spent first
+	TopDays        []DaySpend      `json:"top_days"`       // The most expensive days, most spent first
+}
+
+// BudgetStatus compares a month's spend with the monthly budget. Amounts are in
+// minor units of the reporting currency.
+type BudgetStatus struct {
+	Year           int      `json:"year"`
+	Month          int      `json:"month"`
+	Currency       string   `json:"currency"`
+	MissingRates   []string `json:"missing_rates"` // Currencies left out of Spent for lack of an exchange rate
+	Budget         int64    `json:"budget"`        // 0 when no budget is set, leaving the fields below unset
+	Spent          int64    `json:"spent"`
+	Remaining      int64    `json:"remaining"` // Negative once the budget is exceeded
+	Over           bool     `json:"over"`
+	DaysLeft       int      `json:"days_left"`       // Days after the current one until the month ends
+	DailyAllowance int64    `json:"daily_allowance"` // Remaining spread over the rest of the month, today included
+}
+
+// perUnit divides an amount in minor units, rounding to the nearest minor unit;
+// it is 0 when there is nothing to divide by
+func perUnit(amount int64, count float64) int64 {
+	if count <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(amount) / count))
+}
+
+// GetSpendingReport breaks down the spend on the drinking days from from through to
+func GetSpendingReport(s Store, from, to Date) (SpendingReport, error) {
+	report := SpendingReport{From: from, To: to, MissingRates: []string{}, Categories: []CategorySpend{}, TopDays: []DaySpend{}}
+
+	stats, err := GetStats(s, from, to)
+	if err != nil {
+		return report, err
+	}
+
+	def, err := GetStandardDrink(s)
+	if err != nil {
+		return report, err
+	}
+
+	money, err := newMoneyTotal(s)
+	if err != nil {
+		return report, err
+	}
+
+	// The days ranked are those the totals count
+	last, err := lastElapsedDay(s, to)
+	if err != nil {
+		return report, err
+	}
+	days, err := GetDrinkingDayRecords(s, from, last)
+	if err != nil {
+		return report, err
+	}
+
+	report.Currency = stats.Currency
+	report.MissingRates = stats.MissingRates
+	report.Total = stats.Spend
+	report.StandardDrinks = stats.StandardDrinks
+	report.CostPerDrink = perUnit(stats.Spend, stats.StandardDrinks)
+	report.PerDay = perUnit(stats.Spend, float64(stats.Days))
+
+	for _, category := range stats.Categories {
+		report.Categories = append(report.Categories, CategorySpend{
+			Category:       category.Category,
+			Spend:          category.Spend,
+			StandardDrinks: category.StandardDrinks,
+			CostPerDrink:   perUnit(category.Spend, category.StandardDrinks),
+		})
+	}
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Spend > report.Categories[j].Spend
+	})
+
+	for day, records := range days {
+		daySpend := DaySpend{Date: day}
+		grams := 0.0
+		for _, record := range records {
+			daySpend.Spend += money.entryCost(record.Entry)
+			grams += record.Entry.AlcoholGrams
+		}
+		daySpend.StandardDrinks = StandardDrinksFromGrams(grams, def)
+		if daySpend.Spend > 0 {
+			report.TopDays = append(report.TopDays, daySpend)
+		}
+	}
+	sort.Slice(report.TopDays, func(i, j int) bool {
+		if report.TopDays[i].Spend != report.TopDays[j].Spend {
+			return report.TopDays[i].Spend > report.TopDays[j].Spend
+		}
+		return report.TopDays[i].Date.Before(report.TopDays[j].Date)
+	})
+	if len(report.TopDays) > mostExpensiveDays {
+		report.TopDays = report.TopDays[:mostExpensiveDays]
+	}
+
+	return report, nil
+}
+
+// GetPeriodSpending breaks down the spend in the week, month or year holding d
+func GetPeriodSpending(s Store, period string, d Date) (SpendingReport, error) {
+	from, to, err := PeriodBounds(period, d)
+	if err != nil {
+		return SpendingReport{MissingRates: []string{}, Categories: []CategorySpend{}, TopDays: []DaySpend{}}, err
+	}
+
+	report, err := GetSpendingReport(s, from, to)
+	report.Period = period
+	return report, err
+}
+
+// GetMonthlyBudget returns the monthly spending budget; its Amount is 0 if none is set
+func GetMonthlyBudget(s Store) (Money, error) {
+	budget := Money{}
+	_, err := loadSetting(s, monthlyBudgetKey, &budget)
+	return budget, err
+}
+
+// SetMonthlyBudget sets the monthly spending budget; an Amount of 0 removes it
+func SetMonthlyBudget(s Store, budget Money) error {
+	if budget.Amount < 0 {
+		return errors.New("budget must not be negative")
+	}
+
+	currency, err := LookupCurrency(budget.Currency)
+	if err != nil {
+		return err
+	}
+	budget.Currency = currency.Code
+	return saveSetting(s, monthlyBudgetKey, budget)
+}
+
+// GetBudgetStatus compares the spend in a month with the monthly budget, converted
+// to the reporting currency
+func GetBudgetStatus(s Store, year, month int) (BudgetStatus, error) {
+	status := BudgetStatus{Year: year, Month: month, MissingRates: []string{}}
+
+	budget, err := GetMonthlyBudget(s)
+	if err != nil {
+		return status, err
+	}
+
+	today, err := GetCurrentDrinkingDay(s)
+	if err != nil {
+		return status, err
+	}
+
+	from, to, err := PeriodBounds(PeriodMonth, Date{Year: year, Month: month, Day: 1})
+	if err != nil {
+		return status, err
+	}
+
+	stats, err := GetStats(s, from, to)
+	if err != nil {
+		return status, err
+	}
+
+	status.Currency = stats.Currency
+	status.MissingRates = stats.MissingRates
+	status.Spent = stats.Spend
+	if budget.Amount == 0 {
+		return status, nil
+	}
+
+	converted, err := ConvertMoney(s, budget, stats.Currency)
+	if err != nil {
+		return status, err
+	}
+	status.Budget = converted.Amount
+	status.Remaining = status.Budget - stats.Spend
+	status.Over = stats.Spend > status.Budget
+
+	// Only a month in progress has days left to spread the remainder over
+	if !today.Before(from) && !to.Before(today) {
+		status.DaysLeft = int(to.Time().Sub(today.Time()).Hours() / 24)
+		if status.Remaining > 0 {
+			status.DailyAllowance = perUnit(status.Remaining, float64(status.DaysLeft+1))
+		}
+	}
+	return status, nil
+}
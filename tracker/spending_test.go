@@ -0,0 +1,173 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// seedSpendingStore logs a week of drinks paid for in dollars, euros and pounds,
+// with a rate for euros only
+func seedSpendingStore(t *testing.T, s Store) {
+	t.Helper()
+	if err := SetTimezone(s, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetExchangeRate(s, "EUR", "USD", 1.2); err != nil {
+		t.Fatal(err)
+	}
+
+	drinks := []struct {
+		day      int
+		drink    string
+		quantity float64
+		cost     int64
+		currency string
+	}{
+		{4, "Beer", 500, 600, "USD"},
+		{4, "Wine", 150, 1000, "EUR"},
+		{5, "Vodka", 44, 500, "USD"},
+		{6, "Beer", 500, 800, "GBP"},
+		{7, "Wine", 150, 2000, "EUR"},
+	}
+	for _, d := range drinks {
+		at := time.Date(2024, 3, d.day, 20, 0, 0, 0, time.UTC)
+		mustAdd(t, s, DateOf(at), DayData{Alcohol: d.drink, Quantity: d.quantity, Cost: d.cost, Currency: d.currency, ConsumedAt: at})
+	}
+}
+
+func TestSpendingReportMixedCurrencies(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		seedSpendingStore(t, s)
+
+		report, err := GetPeriodSpending(s, PeriodWeek, Date{2024, 3, 6})
+		if err != nil {
+			t.Fatalf("GetPeriodSpending: %v", err)
+		}
+
+		// The pounds can't be converted, but the beer still counts as drinks
+		beer := StandardDrinksFromGrams(2*AlcoholGrams(500, 5), USStandardDrink)
+		wine := StandardDrinksFromGrams(2*AlcoholGrams(150, 12), USStandardDrink)
+		vodka := StandardDrinksFromGrams(AlcoholGrams(44, 40), USStandardDrink)
+		drinks := beer + wine + vodka
+		if report.Currency != "USD" || report.Total != 4700 || len(report.MissingRates) != 1 || report.MissingRates[0] != "GBP" {
+			t.Errorf("total = %d %s missing %v, want 4700 USD missing GBP", report.Total, report.Currency, report.MissingRates)
+		}
+		if math.Abs(report.StandardDrinks-drinks) > 1e-9 {
+			t.Errorf("%g standard drinks, want %g", report.StandardDrinks, drinks)
+		}
+		if want := int64(math.Round(4700 / drinks)); report.CostPerDrink != want {
+			t.Errorf("cost per drink = %d, want %d", report.CostPerDrink, want)
+		}
+		if want := int64(math.Round(4700.0 / 7)); report.PerDay != want {
+			t.Errorf("per day = %d, want %d", report.PerDay, want)
+		}
+
+		wantCategories := []CategorySpend{
+			{Category: "Wine", Spend: 3600, CostPerDrink: int64(math.Round(3600 / wine))},
+			{Category: "Beer", Spend: 600, CostPerDrink: int64(math.Round(600 / beer))},
+			{Category: "Vodka", Spend: 500, CostPerDrink: int64(math.Round(500 / vodka))},
+		}
+		if len(report.Categories) != len(wantCategories) {
+			t.Fatalf("categories = %+v, want %+v", report.Categories, wantCategories)
+		}
+		for i, want := range wantCategories {
+			got := report.Categories[i]
+			if got.Category != want.Category || got.Spend != want.Spend || got.CostPerDrink != want.CostPerDrink {
+				t.Errorf("category %d = %+v, want %+v", i, got, want)
+			}
+		}
+
+		// The day paid for in pounds cost nothing that can be counted
+		wantDays := []DaySpend{{Date: Date{2024, 3, 7}, Spend: 2400}, {Date: Date{2024, 3, 4}, Spend: 1800}, {Date: Date{2024, 3, 5}, Spend: 500}}
+		if len(report.TopDays) != len(wantDays) {
+			t.Fatalf("top days = %+v, want %+v", report.TopDays, wantDays)
+		}
+		for i, want := range wantDays {
+			if got := report.TopDays[i]; got.Date != want.Date || got.Spend != want.Spend {
+				t.Errorf("top day %d = %+v, want %+v", i, got, want)
+			}
+		}
+
+		// With a rate for pounds everything is counted
+		if err := SetExchangeRate(s, "GBP", "EUR", 1.25); err != nil {
+			t.Fatal(err)
+		}
+		report, err = GetPeriodSpending(s, PeriodWeek, Date{2024, 3, 6})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Total != 5900 || len(report.MissingRates) != 0 {
+			t.Errorf("total = %d missing %v, want 5900 with nothing missing", report.Total, report.MissingRates)
+		}
+	})
+}
+
+func TestBudgetStatus(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		seedSpendingStore(t, s)
+
+		if status, err := GetBudgetStatus(s, 2024, 3); err != nil || status.Budget != 0 || status.Spent != 4700 || status.Over {
+			t.Errorf("without a budget, status = %+v, %v; want 4700 spent and nothing else", status, err)
+		}
+
+		// 100 EUR is 120 USD
+		if err := SetMonthlyBudget(s, Money{Amount: 10000, Currency: "eur"}); err != nil {
+			t.Fatal(err)
+		}
+		status, err := GetBudgetStatus(s, 2024, 3)
+		if err != nil {
+			t.Fatalf("GetBudgetStatus: %v", err)
+		}
+		if status.Currency != "USD" || status.Budget != 12000 || status.Spent != 4700 || status.Remaining != 7300 || status.Over {
+			t.Errorf("status = %+v, want 4700 of 12000 USD spent with 7300 left", status)
+		}
+		if status.DaysLeft != 0 || status.DailyAllowance != 0 {
+			t.Errorf("a past month has %d days left and %d a day to spend", status.DaysLeft, status.DailyAllowance)
+		}
+
+		if err := SetMonthlyBudget(s, Money{Amount: 3000, Currency: "EUR"}); err != nil {
+			t.Fatal(err)
+		}
+		if status, err := GetBudgetStatus(s, 2024, 3); err != nil || status.Remaining != -1100 || !status.Over {
+			t.Errorf("status = %+v, %v; want 1100 USD over", status, err)
+		}
+
+		if err := SetMonthlyBudget(s, Money{Amount: 3000, Currency: "JPY"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := GetBudgetStatus(s, 2024, 3); err == nil {
+			t.Error("a budget was converted without an exchange rate")
+		}
+	})
+}
+
+func TestBudgetDailyAllowance(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+		today, err := GetCurrentDrinkingDay(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mustAdd(t, s, today, DayData{Alcohol: "Beer", Quantity: 500, Cost: 1000, Currency: "USD",
+			ConsumedAt: time.Date(today.Year, time.Month(today.Month), today.Day, 12, 0, 0, 0, time.UTC)})
+		if err := SetMonthlyBudget(s, Money{Amount: 3100, Currency: "USD"}); err != nil {
+			t.Fatal(err)
+		}
+
+		status, err := GetBudgetStatus(s, today.Year, today.Month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, last, _ := PeriodBounds(PeriodMonth, today)
+		daysLeft := last.Day - today.Day
+		if status.Remaining != 2100 || status.DaysLeft != daysLeft {
+			t.Errorf("status = %+v, want 2100 left over %d more days", status, daysLeft)
+		}
+		if want := int64(math.Round(2100 / float64(daysLeft+1))); status.DailyAllowance != want {
+			t.Errorf("daily allowance = %d, want %d", status.DailyAllowance, want)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Periods stats can be grouped by
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+// CategoryStats totals one drink category over a period
+type CategoryStats struct {
+	Category       string  `json:"category"`
+	Entries        int     `json:"entries"`
+	QuantityML     float64 `json:"quantity_ml"`
+	AlcoholGrams   float64 `json:"alcohol_grams"`
+	StandardDrinks float64 `json:"standard_drinks"`
+	Spend          int64   `json:"spend"` // Minor units of the reporting currency
+}
+
+// PeriodStats totals the drinking days from From through To (inclusive), up to
+// the current drinking day
+type PeriodStats struct {
+	Period               string          `json:"period"` // PeriodWeek, PeriodMonth, PeriodYear or "" for a custom range
+	From                 Date            `json:"from"`
+	To                   Date            `json:"to"`
+	Days                 int             `json:"days"` // Days in the period up to and including the current drinking day
+	DrinkingDays         int             `json:"drinking_days"`
+	AlcoholFreeDays      int             `json:"alcohol_free_days"`
+	Entries              int             `json:"entries"`
+	AlcoholGrams         float64         `json:"alcohol_grams"`
+	StandardDrinks       float64         `json:"standard_drinks"`
+	Spend                int64           `json:"spend"`                   // Minor units of Currency
+	Currency             string          `json:"currency"`                // The reporting currency
+	MissingRates         []string        `json:"missing_rates"`           // Currencies left out of Spend for lack of an exchange rate
+	DrinksPerDay         float64         `json:"drinks_per_day"`          // Averaged over Days
+	DrinksPerDrinkingDay float64         `json:"drinks_per_drinking_day"` // Averaged over DrinkingDays
+	Categories           []CategoryStats `json:"categories"`              // Sorted by standard drinks, most first
+	DrinksByHour         []float64       `json:"drinks_by_hour"`          // Standard drinks had in each hour 0-23 of the user's clock
+}
+
+// PeriodBounds returns the first and last day of the week (Monday to Sunday),
+// month or year holding d
+func PeriodBounds(period string, d Date) (Date, Date, error) {
+	switch period {
+	case PeriodWeek:
+		from, to := WeekOf(d)
+		return from, to, nil
+	case PeriodMonth:
+		first := time.Date(d.Year, time.Month(d.Month), 1, 0, 0, 0, 0, time.UTC)
+		return DateOf(first), DateOf(first.AddDate(0, 1, -1)), nil
+	case PeriodYear:
+		return Date{Year: d.Year, Month: 1, Day: 1}, Date{Year: d.Year, Month: 12, Day: 31}, nil
+	}
+	return Date{}, Date{}, fmt.Errorf("unknown period '%s'", period)
+}
+
+// GetDrinkingDayRecords returns the records attributed to each drinking day from
+// from through to, keyed by drinking day. Entries had after midnight but before the
+// rollover hour are counted towards the day before, as in GetDrinkingDayEntries.
+func GetDrinkingDayRecords(s Store, from, to Date) (map[Date][]Record, error) {
+	days := map[Date][]Record{}
+
+	rollover, err := GetDayRolloverHour(s)
+	if err != nil {
+		return days, err
+	}
+
+	// The night of the last day can run into the next date
+	records, err := GetEntriesInRange(s, from.Time(), to.Time().AddDate(0, 0, 1))
+	if err != nil {
+		return days, err
+	}
+
+	for _, record := range records {
+		day := DateOf(entryDrinkingDay(record.Date.Year, record.Date.Month, record.Date.Day, record.Entry, rollover))
+		if day.Before(from) || to.Before(day) {
+			continue
+		}
+		days[day] = append(days[day], record)
+	}
+	return days, nil
+}
+
+// lastElapsedDay returns to, or the current drinking day if to is still to come
+func lastElapsedDay(s Store, to Date) (Date, error) {
+	today, err := GetCurrentDrinkingDay(s)
+	if err != nil || today.Before(to) {
+		return today, err
+	}
+	return to, nil
+}
+
+// GetStats totals the drinking days from from through to (inclusive). A range
+// running past the current drinking day is cut off there, so days still to come,
+// and any entries logged for them, are left out.
+func GetStats(s Store, from, to Date) (PeriodStats, error) {
+	stats := PeriodStats{From: from, To: to, Categories: []CategoryStats{}, MissingRates: []string{}, DrinksByHour: make([]float64, 24)}
+	if to.Before(from) {
+		return stats, fmt.Errorf("range end %s is before its start %s", to, from)
+	}
+
+	def, err := GetStandardDrink(s)
+	if err != nil {
+		return stats, err
+	}
+
+	loc, err := GetLocation(s)
+	if err != nil {
+		return stats, err
+	}
+
+	last, err := lastElapsedDay(s, to)
+	if err != nil {
+		return stats, err
+	}
+
+	money, err := newMoneyTotal(s)
+	if err != nil {
+		return stats, err
+	}
+	stats.Currency = money.currency.Code
+
+	// Days still to come are neither drinking nor alcohol-free
+	if last.Before(from) {
+		return stats, nil
+	}
+	stats.Days = int(last.Time().Sub(from.Time()).Hours()/24) + 1
+
+	days, err := GetDrinkingDayRecords(s, from, last)
+	if err != nil {
+		return stats, err
+	}
+
+	categories := map[string]*CategoryStats{}
+	for _, records := range days {
+		stats.DrinkingDays++
+		for _, record := range records {
+			entry := record.Entry
+			cost := money.entryCost(entry)
+			category, ok := categories[record.Category]
+			if !ok {
+				category = &CategoryStats{Category: record.Category}
+				categories[record.Category] = category
+			}
+
+			category.Entries++
+			category.QuantityML += entry.Quantity
+			category.AlcoholGrams += entry.AlcoholGrams
+			category.Spend += cost
+
+			stats.Entries++
+			stats.AlcoholGrams += entry.AlcoholGrams
+			stats.Spend += cost
+			stats.DrinksByHour[entry.ConsumedAt.In(loc).Hour()] += StandardDrinksFromGrams(entry.AlcoholGrams, def)
+		}
+	}
+
+	stats.StandardDrinks = StandardDrinksFromGrams(stats.AlcoholGrams, def)
+	stats.MissingRates = money.missingRates()
+	stats.AlcoholFreeDays = stats.Days - stats.DrinkingDays
+	if stats.Days > 0 {
+		stats.DrinksPerDay = stats.StandardDrinks / float64(stats.Days)
+	}
+	if stats.DrinkingDays > 0 {
+		stats.DrinksPerDrinkingDay = stats.StandardDrinks / float64(stats.DrinkingDays)
+	}
+
+	for _, category := range categories {
+		category.StandardDrinks = StandardDrinksFromGrams(category.AlcoholGrams, def)
+		stats.Categories = append(stats.Categories, *category)
+	}
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		if stats.Categories[i].AlcoholGrams != stats.Categories[j].AlcoholGrams {
+			return stats.Categories[i].AlcoholGrams > stats.Categories[j].AlcoholGrams
+		}
+		return stats.Categories[i].Category < stats.Categories[j].Category
+	})
+
+	return stats, nil
+}
+
+// GetPeriodStats totals the week, month or year holding d
+func GetPeriodStats(s Store, period string, d Date) (PeriodStats, error) {
+	from, to, err := PeriodBounds(period, d)
+	if err != nil {
+		return PeriodStats{Categories: []CategoryStats{}, MissingRates: []string{}, DrinksByHour: make([]float64, 24)}, err
+	}
+
+	stats, err := GetStats(s, from, to)
+	stats.Period = period
+	return stats, err
+}
@@ -0,0 +1,186 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// seedStatsStore logs drinks across 2024 in UTC with a 05:00 rollover
+func seedStatsStore(t *testing.T, s Store) {
+	t.Helper()
+	if err := SetTimezone(s, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDayRolloverHour(s, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	drinks := []struct {
+		at       time.Time
+		drink    string
+		quantity float64
+		cost     int64
+	}{
+		{time.Date(2024, 3, 4, 19, 0, 0, 0, time.UTC), "Beer", 500, 600},
+		{time.Date(2024, 3, 4, 21, 0, 0, 0, time.UTC), "Beer", 500, 600},
+		{time.Date(2024, 3, 6, 20, 0, 0, 0, time.UTC), "Wine", 150, 900},
+		{time.Date(2024, 3, 11, 1, 30, 0, 0, time.UTC), "Beer", 500, 600}, // The night of Sunday the 10th
+		{time.Date(2024, 3, 20, 22, 0, 0, 0, time.UTC), "Vodka", 44, 500},
+		{time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC), "Wine", 150, 900},
+		{time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC), "Wine", 150, 900}, // Still New Year's Eve
+	}
+	for _, d := range drinks {
+		mustAdd(t, s, DateOf(d.at), DayData{Alcohol: d.drink, Quantity: d.quantity, Cost: d.cost, Currency: "USD", ConsumedAt: d.at})
+	}
+}
+
+func TestPeriodStats(t *testing.T) {
+	beer := AlcoholGrams(500, 5)
+	wine := AlcoholGrams(150, 12)
+	vodka := AlcoholGrams(44, 40)
+
+	tests := []struct {
+		period       string
+		date         Date
+		days         int
+		drinkingDays int
+		entries      int
+		grams        float64
+		spend        int64
+		categories   []string // Most alcohol first
+	}{
+		{PeriodWeek, Date{2024, 3, 6}, 7, 3, 4, 3*beer + wine, 2700, []string{"Beer", "Wine"}},
+		{PeriodMonth, Date{2024, 3, 15}, 31, 4, 5, 3*beer + wine + vodka, 3200, []string{"Beer", "Wine", "Vodka"}},
+		{PeriodYear, Date{2024, 7, 1}, 366, 5, 7, 3*beer + 3*wine + vodka, 5000, []string{"Beer", "Wine", "Vodka"}},
+		{PeriodYear, Date{2023, 7, 1}, 365, 0, 0, 0, 0, []string{}},
+	}
+
+	forEachStore(t, func(t *testing.T, s Store) {
+		seedStatsStore(t, s)
+		for _, test := range tests {
+			stats, err := GetPeriodStats(s, test.period, test.date)
+			if err != nil {
+				t.Fatalf("GetPeriodStats(%s, %s): %v", test.period, test.date, err)
+			}
+			if stats.Days != test.days || stats.DrinkingDays != test.drinkingDays || stats.AlcoholFreeDays != test.days-test.drinkingDays {
+				t.Errorf("%s of %s: %d days, %d drinking, %d alcohol-free; want %d, %d and %d", test.period, test.date,
+					stats.Days, stats.DrinkingDays, stats.AlcoholFreeDays, test.days, test.drinkingDays, test.days-test.drinkingDays)
+			}
+			if stats.Entries != test.entries || math.Abs(stats.AlcoholGrams-test.grams) > 1e-9 || stats.Spend != test.spend {
+				t.Errorf("%s of %s: %d entries, %g g, spend %d; want %d, %g g and %d", test.period, test.date,
+					stats.Entries, stats.AlcoholGrams, stats.Spend, test.entries, test.grams, test.spend)
+			}
+			if want := StandardDrinksFromGrams(test.grams, USStandardDrink); math.Abs(stats.StandardDrinks-want) > 1e-9 {
+				t.Errorf("%s of %s: %g standard drinks, want %g", test.period, test.date, stats.StandardDrinks, want)
+			}
+
+			if len(stats.Categories) != len(test.categories) {
+				t.Errorf("%s of %s: categories %+v, want %v", test.period, test.date, stats.Categories, test.categories)
+				continue
+			}
+			total := 0
+			for i, category := range stats.Categories {
+				if category.Category != test.categories[i] {
+					t.Errorf("%s of %s: category %d is %s, want %s", test.period, test.date, i, category.Category, test.categories[i])
+				}
+				total += category.Entries
+			}
+			if total != test.entries {
+				t.Errorf("%s of %s: categories hold %d entries, want %d", test.period, test.date, total, test.entries)
+			}
+		}
+	})
+}
+
+func TestWeekCategoryBreakdown(t *testing.T) {
+	s := NewMemoryStore()
+	seedStatsStore(t, s)
+
+	stats, err := GetPeriodStats(s, PeriodWeek, Date{2024, 3, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CategoryStats{
+		{Category: "Beer", Entries: 3, QuantityML: 1500, AlcoholGrams: 3 * AlcoholGrams(500, 5), Spend: 1800},
+		{Category: "Wine", Entries: 1, QuantityML: 150, AlcoholGrams: AlcoholGrams(150, 12), Spend: 900},
+	}
+	for i := range want {
+		want[i].StandardDrinks = StandardDrinksFromGrams(want[i].AlcoholGrams, USStandardDrink)
+		if got := stats.Categories[i]; got != want[i] {
+			t.Errorf("category %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if want := stats.StandardDrinks / 3; math.Abs(stats.DrinksPerDrinkingDay-want) > 1e-9 {
+		t.Errorf("%g drinks per drinking day, want %g", stats.DrinksPerDrinkingDay, want)
+	}
+}
+
+func TestStatsStopAtToday(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+		today, err := GetCurrentDrinkingDay(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addAtNoon(t, s, daysBefore(today, 1))
+		addAtNoon(t, s, daysBefore(today, -2)) // Logged ahead of time
+
+		// Three days so far, one of them with a drink
+		stats, err := GetStats(s, daysBefore(today, 2), daysBefore(today, -4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Days != 3 || stats.DrinkingDays != 1 || stats.AlcoholFreeDays != 2 || stats.Entries != 1 {
+			t.Errorf("stats = %d days, %d drinking, %d alcohol-free, %d entries; want 3, 1, 2 and 1",
+				stats.Days, stats.DrinkingDays, stats.AlcoholFreeDays, stats.Entries)
+		}
+
+		// A range that hasn't started has nothing in it
+		stats, err = GetStats(s, daysBefore(today, -1), daysBefore(today, -4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Days != 0 || stats.DrinkingDays != 0 || stats.AlcoholFreeDays != 0 || stats.Entries != 0 {
+			t.Errorf("stats of a future range = %+v, want nothing counted", stats)
+		}
+	})
+}
+
+func TestDrinksByHour(t *testing.T) {
+	s := NewMemoryStore()
+	seedStatsStore(t, s)
+
+	beer := StandardDrinksFromGrams(AlcoholGrams(500, 5), USStandardDrink)
+	wine := StandardDrinksFromGrams(AlcoholGrams(150, 12), USStandardDrink)
+	tests := []struct {
+		zone  string
+		hours map[int]float64
+	}{
+		{"UTC", map[int]float64{1: beer, 19: beer, 20: wine, 21: beer}},
+		{"Asia/Tokyo", map[int]float64{4: beer, 5: wine, 6: beer, 10: beer}}, // The same drinks, 9 hours later on the clock
+	}
+	for _, test := range tests {
+		if err := SetTimezone(s, test.zone); err != nil {
+			t.Fatal(err)
+		}
+		stats, err := GetPeriodStats(s, PeriodWeek, Date{2024, 3, 6})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats.DrinksByHour) != 24 {
+			t.Fatalf("%s: %d hours, want 24", test.zone, len(stats.DrinksByHour))
+		}
+		for hour, drinks := range stats.DrinksByHour {
+			if math.Abs(drinks-test.hours[hour]) > 1e-9 {
+				t.Errorf("%s: %g drinks at %02d:00, want %g", test.zone, drinks, hour, test.hours[hour])
+			}
+		}
+	}
+
+	if stats, err := GetPeriodStats(s, PeriodYear, Date{2023, 7, 1}); err != nil || len(stats.DrinksByHour) != 24 {
+		t.Errorf("a year without drinks has %d hours, %v; want 24 empty ones", len(stats.DrinksByHour), err)
+	}
+}
@@ -0,0 +1,238 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Store is the persistence layer behind the tracker. Entries are grouped
+// hierarchically as Year → Month → Day → Category, matching the on-disk layout.
+type Store interface {
+	// AddEntry appends an entry to the given day and category, assigning an ID if it has none
+	AddEntry(year, month, day int, category string, data DayData) error
+
+	// GetEntriesByDate returns all entries on a day, keyed by category
+	GetEntriesByDate(year, month, day int) (map[string][]DayData, error)
+
+	// GetEntry returns the entry with the given ID on a day
+	GetEntry(year, month, day int, id string) (DayData, error)
+
+	// UpdateEntry atomically replaces the entry with the given ID, keeping its ID and
+	// moving it to the new date and to the category in data.Alcohol if they differ
+	UpdateEntry(year, month, day int, id string, newYear, newMonth, newDay int, data DayData) error
+
+	// DeleteEntry removes the entry with the given ID from a day
+	DeleteEntry(year, month, day int, id string) error
+
+	// GetEntriesByYear returns all entries in a year (structured as Month → Day → Category)
+	GetEntriesByYear(year int) (map[string]map[string]map[string][]DayData, error)
+
+	// GetEntriesByYearAndMonth returns all entries in a month (structured as Day → Category)
+	GetEntriesByYearAndMonth(year, month int) (map[string]map[string][]DayData, error)
+
+	// GetRecordsInRange returns every entry stored between two dates, inclusive, in date order
+	GetRecordsInRange(from, to Date) ([]Record, error)
+
+	// FindLatestEntryDate returns the most recent day holding any entry
+	FindLatestEntryDate() (int, int, int, error)
+
+	// GetDrinks returns every drink in the catalog, including archived ones
+	GetDrinks() ([]Drink, error)
+
+	// SaveDrink creates or replaces the catalog drink with the same name
+	SaveDrink(drink Drink) error
+
+	// GetSetting returns the raw value stored under a settings key, or nil if it is unset
+	GetSetting(key string) ([]byte, error)
+
+	// PutSetting stores a raw value under a settings key
+	PutSetting(key string, value []byte) error
+
+	// UpdateSetting atomically replaces the raw value under a settings key (nil if
+	// unset) with what update returns. Nothing is written if update fails.
+	UpdateSetting(key string, update func(value []byte) ([]byte, error)) error
+
+	// GetSettings returns every raw settings value, keyed by settings key
+	GetSettings() (map[string][]byte, error)
+
+	// Restore writes a backup's entries, catalog and settings in one step. With replace
+	// set, those are cleared first, apart from local settings such as the API token;
+	// otherwise entries whose ID is already stored on any date, and catalog drinks that
+	// already exist, are kept as they are. Settings that exist are merged by
+	// mergeSetting.
+	Restore(backup Backup, replace bool) (RestoreResult, error)
+
+	// Close releases the underlying resources
+	Close() error
+}
+
+// Record is an entry together with the calendar date and category it is stored under
+type Record struct {
+	Date     Date    `json:"date"`
+	Category string  `json:"category"`
+	Entry    DayData `json:"entry"`
+}
+
+// GetEntriesInRange returns the entries stored on the calendar dates of from
+// through to (inclusive, each in its own time zone) as a flat list sorted by
+// date and then by consumption time
+func GetEntriesInRange(s Store, from, to time.Time) ([]Record, error) {
+	fromDate, toDate := DateOf(from), DateOf(to)
+	if toDate.Before(fromDate) {
+		return []Record{}, fmt.Errorf("range end %s is before its start %s", toDate, fromDate)
+	}
+
+	records, err := s.GetRecordsInRange(fromDate, toDate)
+	if err != nil {
+		return []Record{}, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Date != records[j].Date {
+			return records[i].Date.Before(records[j].Date)
+		}
+		return records[i].Entry.ConsumedAt.Before(records[j].Entry.ConsumedAt)
+	})
+	return records, nil
+}
+
+// NotFoundError reports that nothing of a kind is stored under an ID
+type NotFoundError struct {
+	Kind string // "entry" or "goal"
+	ID   string
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("no %s with id %s", e.Kind, e.ID)
+}
+
+// FindRecord looks an entry up by ID alone, for callers that don't know its date
+func FindRecord(s Store, id string) (Record, error) {
+	records, err := s.GetRecordsInRange(firstTrackedDate, lastTrackedDate)
+	if err != nil {
+		return Record{}, err
+	}
+
+	for _, record := range records {
+		if record.Entry.ID == id {
+			return record, nil
+		}
+	}
+	return Record{}, NotFoundError{Kind: "entry", ID: id}
+}
+
+// AddEntry stamps the entry's ABV, alcohol grams and creation time and stores it
+// under its drink category. A zero ConsumedAt is taken to mean "now", and an
+// empty Currency the default currency.
+func AddEntry(s Store, year, month, day int, data DayData) error {
+	if err := stampAlcohol(s, &data); err != nil {
+		return err
+	}
+	if err := stampCurrency(s, &data); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if data.CreatedAt.IsZero() {
+		data.CreatedAt = now
+	}
+	data.UpdatedAt = now
+	if data.ConsumedAt.IsZero() {
+		data.ConsumedAt = now
+	}
+
+	return s.AddEntry(year, month, day, data.Alcohol, data)
+}
+
+// UpdateEntry validates the target date and edits the entry with the given ID in one step.
+// Clear data.ABV to pick up the catalog ABV, e.g. when the drink changes.
+func UpdateEntry(s Store, year, month, day int, id string, newYear, newMonth, newDay int, data DayData) error {
+	if err := ValidateDate(newDay, newMonth, newYear); err != nil {
+		return err
+	}
+	if err := stampAlcohol(s, &data); err != nil {
+		return err
+	}
+	if err := stampCurrency(s, &data); err != nil {
+		return err
+	}
+	data.UpdatedAt = time.Now()
+	return s.UpdateEntry(year, month, day, id, newYear, newMonth, newDay, data)
+}
+
+// Get all entries for a specific year, month, and day as a flat list
+func GetEntriesByDateList(s Store, year, month, day int) ([]DayData, error) {
+	allEntries := []DayData{} // Always initialized as an empty slice
+
+	entries, err := s.GetEntriesByDate(year, month, day)
+	if err != nil {
+		return allEntries, nil // No data found, return empty list
+	}
+
+	for _, categoryEntries := range entries {
+		allEntries = append(allEntries, categoryEntries...)
+	}
+
+	sortByConsumedAt(allEntries)
+	return allEntries, nil
+}
+
+// Get all entries for a specific year, month, day, and category
+func GetEntriesByDateCategory(s Store, year, month, day int, category string) ([]DayData, error) {
+	entries, err := s.GetEntriesByDate(year, month, day)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryEntries, ok := entries[category]
+	if !ok {
+		return nil, fmt.Errorf("no data found for category '%s' on %02d-%02d-%d", category, day, month, year)
+	}
+
+	return categoryEntries, nil
+}
+
+// PrintDaysInYear prints all days with their associated DayData entries for a given year
+func PrintDaysInYear(s Store, year int) error {
+	months, err := s.GetEntriesByYear(year)
+	if err != nil {
+		fmt.Printf("No data found for year %d\n", year)
+		return nil
+	}
+
+	for _, monthKey := range sortedKeys(months) {
+		days := months[monthKey]
+		for _, dayKey := range sortedKeys(days) {
+			fmt.Printf("Entries for date: %d-%s-%s\n", year, monthKey, dayKey)
+
+			categories := days[dayKey]
+			for _, categoryKey := range sortedKeys(categories) {
+				fmt.Printf("  Category: %s\n", categoryKey)
+				for _, entry := range categories[categoryKey] {
+					fmt.Printf("    ID: %s, Alcohol: %s, Quantity: %g mL, Cost: %s, Consumed: %s\n",
+						entry.ID, entry.Alcohol, entry.Quantity, formatCost(entry), entry.ConsumedAt.Format(time.RFC3339))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortByConsumedAt orders entries chronologically by consumption time
+func sortByConsumedAt(entries []DayData) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ConsumedAt.Before(entries[j].ConsumedAt)
+	})
+}
+
+// sortedKeys returns the keys of a string-keyed map in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,260 @@
+package tracker
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestBoltStore opens a BoltStore on a fresh file that is removed after the test
+func newTestBoltStore(t *testing.T) *BoltStore {
+	t.Helper()
+	s, err := InitDB(filepath.Join(t.TempDir(), DefaultDBFile))
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// forEachStore runs a test against every Store implementation
+func forEachStore(t *testing.T, test func(t *testing.T, s Store)) {
+	t.Run("memory", func(t *testing.T) { test(t, NewMemoryStore()) })
+	t.Run("bolt", func(t *testing.T) { test(t, newTestBoltStore(t)) })
+}
+
+// mustAdd logs an entry through AddEntry and returns it as stored
+func mustAdd(t *testing.T, s Store, date Date, data DayData) DayData {
+	t.Helper()
+	if data.ID == "" {
+		data.ID = NewID()
+	}
+	if err := AddEntry(s, date.Year, date.Month, date.Day, data); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	entry, err := s.GetEntry(date.Year, date.Month, date.Day, data.ID)
+	if err != nil {
+		t.Fatalf("GetEntry: %v", err)
+	}
+	return entry
+}
+
+func TestStoreAddAndGet(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		date := Date{Year: 2024, Month: 3, Day: 5}
+		consumed := time.Date(2024, 3, 5, 20, 30, 0, 0, time.UTC)
+		entry := mustAdd(t, s, date, DayData{Alcohol: "Beer", Quantity: 500, Cost: 450, ConsumedAt: consumed})
+
+		if entry.ABV != 5 {
+			t.Errorf("ABV = %g, want the catalog's 5", entry.ABV)
+		}
+		if want := AlcoholGrams(500, 5); entry.AlcoholGrams != want {
+			t.Errorf("AlcoholGrams = %g, want %g", entry.AlcoholGrams, want)
+		}
+		if entry.Currency != USDollar.Code {
+			t.Errorf("Currency = %q, want the default %q", entry.Currency, USDollar.Code)
+		}
+		if !entry.ConsumedAt.Equal(consumed) {
+			t.Errorf("ConsumedAt = %v, want %v", entry.ConsumedAt, consumed)
+		}
+		if entry.CreatedAt.IsZero() || entry.UpdatedAt.IsZero() {
+			t.Error("creation and update times were not stamped")
+		}
+
+		day, err := s.GetEntriesByDate(date.Year, date.Month, date.Day)
+		if err != nil {
+			t.Fatalf("GetEntriesByDate: %v", err)
+		}
+		if len(day["Beer"]) != 1 || day["Beer"][0].ID != entry.ID {
+			t.Errorf("GetEntriesByDate = %v, want the entry under Beer", day)
+		}
+
+		if _, err := s.GetEntry(date.Year, date.Month, date.Day, "missing"); !errors.As(err, &NotFoundError{}) {
+			t.Errorf("GetEntry of an unknown ID: err = %v, want a NotFoundError", err)
+		}
+	})
+}
+
+func TestAddEntryNeedsKnownDrinkOrABV(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		err := AddEntry(s, 2024, 3, 5, DayData{ID: NewID(), Alcohol: "Moonshine", Quantity: 50})
+		if err == nil {
+			t.Error("an unknown drink without an ABV was stored")
+		}
+
+		entry := mustAdd(t, s, Date{Year: 2024, Month: 3, Day: 5}, DayData{Alcohol: "Moonshine", Quantity: 50, ABV: 60})
+		if entry.ABV != 60 || entry.AlcoholGrams != AlcoholGrams(50, 60) {
+			t.Errorf("stored %+v, want the given 60%% ABV", entry)
+		}
+	})
+}
+
+func TestTotalDrinksUseStoredAlcohol(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		date := Date{Year: 2024, Month: 3, Day: 5}
+		mustAdd(t, s, date, DayData{Alcohol: "Beer", Quantity: 500})
+
+		// Catalog edits must not rewrite history
+		if err := SaveDrink(s, Drink{Name: "Beer", ABV: 8, ServingML: 500}); err != nil {
+			t.Fatal(err)
+		}
+		total, err := GetTotalDrinksOnDay(s, date.Year, date.Month, date.Day)
+		if want := StandardDrinksFromGrams(AlcoholGrams(500, 5), USStandardDrink); err != nil || total != want {
+			t.Errorf("GetTotalDrinksOnDay = %g, %v; want %g", total, err, want)
+		}
+	})
+}
+
+func TestStoreUpdateMovesEntry(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		from := Date{Year: 2024, Month: 3, Day: 5}
+		to := Date{Year: 2024, Month: 4, Day: 1}
+		entry := mustAdd(t, s, from, DayData{Alcohol: "Beer", Quantity: 500})
+
+		entry.Alcohol = "Wine"
+		entry.ABV = 0
+		entry.Quantity = 150
+		if err := UpdateEntry(s, from.Year, from.Month, from.Day, entry.ID, to.Year, to.Month, to.Day, entry); err != nil {
+			t.Fatalf("UpdateEntry: %v", err)
+		}
+
+		moved, err := s.GetEntry(to.Year, to.Month, to.Day, entry.ID)
+		if err != nil {
+			t.Fatalf("GetEntry on the new date: %v", err)
+		}
+		if moved.Alcohol != "Wine" || moved.ABV != 12 || moved.Quantity != 150 {
+			t.Errorf("moved entry = %+v, want 150 mL of Wine at 12%%", moved)
+		}
+
+		// The old day was left empty, so it must be gone
+		if _, err := s.GetEntriesByDate(from.Year, from.Month, from.Day); err == nil {
+			t.Error("the emptied day is still stored")
+		}
+		if err := UpdateEntry(s, from.Year, from.Month, from.Day, entry.ID, to.Year, to.Month, to.Day, entry); err == nil {
+			t.Error("updating the entry at its old date succeeded")
+		}
+		if err := UpdateEntry(s, to.Year, to.Month, to.Day, entry.ID, 2024, 2, 30, entry); err == nil {
+			t.Error("moving the entry to an invalid date succeeded")
+		}
+	})
+}
+
+func TestStoreDeletePrunesDays(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		date := Date{Year: 2024, Month: 3, Day: 5}
+		first := mustAdd(t, s, date, DayData{Alcohol: "Beer", Quantity: 500})
+		second := mustAdd(t, s, date, DayData{Alcohol: "Beer", Quantity: 330})
+
+		if err := s.DeleteEntry(date.Year, date.Month, date.Day, first.ID); err != nil {
+			t.Fatalf("DeleteEntry: %v", err)
+		}
+		entries, err := GetEntriesByDateList(s, date.Year, date.Month, date.Day)
+		if err != nil || len(entries) != 1 || entries[0].ID != second.ID {
+			t.Fatalf("after deleting one entry: %v, %v; want only the second", entries, err)
+		}
+
+		if err := s.DeleteEntry(date.Year, date.Month, date.Day, second.ID); err != nil {
+			t.Fatalf("DeleteEntry: %v", err)
+		}
+		if _, _, _, err := s.FindLatestEntryDate(); err == nil {
+			t.Error("FindLatestEntryDate found a day after every entry was deleted")
+		}
+		if _, err := s.GetEntriesByYear(date.Year); err == nil {
+			t.Error("the emptied year is still stored")
+		}
+	})
+}
+
+func TestStoreRecordsInRange(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		dates := []Date{
+			{Year: 2023, Month: 12, Day: 31},
+			{Year: 2024, Month: 1, Day: 1},
+			{Year: 2024, Month: 1, Day: 15},
+			{Year: 2024, Month: 2, Day: 1},
+			{Year: 2025, Month: 1, Day: 1},
+		}
+		for _, date := range dates {
+			mustAdd(t, s, date, DayData{Alcohol: "Beer", Quantity: 500})
+		}
+
+		records, err := s.GetRecordsInRange(Date{Year: 2024, Month: 1, Day: 1}, Date{Year: 2024, Month: 2, Day: 1})
+		if err != nil {
+			t.Fatalf("GetRecordsInRange: %v", err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("got %d records, want 3", len(records))
+		}
+		for i, want := range dates[1:4] {
+			if records[i].Date != want {
+				t.Errorf("record %d is on %s, want %s", i, records[i].Date, want)
+			}
+		}
+
+		year, month, day, err := s.FindLatestEntryDate()
+		if err != nil || (Date{Year: year, Month: month, Day: day}) != dates[4] {
+			t.Errorf("FindLatestEntryDate = %d-%d-%d, %v; want %s", year, month, day, err, dates[4])
+		}
+
+		record, err := FindRecord(s, records[1].Entry.ID)
+		if err != nil || record.Date != dates[2] {
+			t.Errorf("FindRecord = %+v, %v; want the entry on %s", record, err, dates[2])
+		}
+		if _, err := FindRecord(s, "missing"); !errors.As(err, &NotFoundError{}) {
+			t.Errorf("FindRecord of an unknown ID: err = %v, want a NotFoundError", err)
+		}
+	})
+}
+
+func TestStoreCatalogAndSettings(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SaveDrink(s, Drink{Name: " Cider ", ABV: 4.5, ServingML: 500}); err != nil {
+			t.Fatalf("SaveDrink: %v", err)
+		}
+		if err := SaveDrink(s, Drink{Name: "Bad", ABV: 120, ServingML: 500}); err == nil {
+			t.Error("SaveDrink accepted an ABV above 100")
+		}
+		if err := ArchiveDrink(s, "Soju", true); err != nil {
+			t.Fatalf("ArchiveDrink: %v", err)
+		}
+
+		types, err := GetAlcoholTypes(s)
+		if err != nil {
+			t.Fatalf("GetAlcoholTypes: %v", err)
+		}
+		seen := map[string]bool{}
+		for _, name := range types {
+			seen[name] = true
+		}
+		if !seen["Cider"] || seen["Soju"] {
+			t.Errorf("GetAlcoholTypes = %v, want Cider and not the archived Soju", types)
+		}
+
+		catalog, err := LoadCatalog(s)
+		if err != nil {
+			t.Fatalf("LoadCatalog: %v", err)
+		}
+		if abv, err := catalog.ABV("Soju"); err != nil || abv != 16 {
+			t.Errorf("ABV of the archived Soju = %g, %v; want 16", abv, err)
+		}
+		if _, err := catalog.ABV("Moonshine"); err == nil {
+			t.Error("an unknown drink has an ABV")
+		}
+
+		if err := SetDayRolloverHour(s, 5); err != nil {
+			t.Fatalf("SetDayRolloverHour: %v", err)
+		}
+		if hour, err := GetDayRolloverHour(s); err != nil || hour != 5 {
+			t.Errorf("GetDayRolloverHour = %d, %v; want 5", hour, err)
+		}
+
+		settings, err := s.GetSettings()
+		if err != nil {
+			t.Fatalf("GetSettings: %v", err)
+		}
+		if string(settings[dayRolloverKey]) != "5" {
+			t.Errorf("GetSettings()[%s] = %q, want 5", dayRolloverKey, settings[dayRolloverKey])
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package tracker
+
+// Streak kinds
+const (
+	SoberStreak    = "sober"
+	DrinkingStreak = "drinking"
+)
+
+// Streak is a run of consecutive alcohol-free or drinking days
+type Streak struct {
+	Kind    string `json:"kind"` // SoberStreak or DrinkingStreak
+	Start   Date   `json:"start"`
+	End     Date   `json:"end"`
+	Days    int    `json:"days"`
+	Current bool   `json:"current"` // Runs up to the current drinking day
+}
+
+// StreakSummary collects the streaks from the first drinking day to the current one
+type StreakSummary struct {
+	CurrentSober    int      `json:"current_sober"`    // 0 when the current day has drinks
+	CurrentDrinking int      `json:"current_drinking"` // 0 when the current day is alcohol-free
+	LongestSober    Streak   `json:"longest_sober"`
+	LongestDrinking Streak   `json:"longest_drinking"`
+	History         []Streak `json:"history"` // Alternating sober and drinking streaks, oldest first
+}
+
+// firstTrackedDate is the earliest date ValidateDate accepts
+var firstTrackedDate = Date{Year: 2000, Month: 1, Day: 1}
+
+// GetStreaks works out the sober and drinking streaks from the stored entries.
+// Nothing is cached, so inserting or deleting a past entry is reflected straight away.
+// Entries logged for days after the current drinking day are ignored.
+func GetStreaks(s Store) (StreakSummary, error) {
+	summary := StreakSummary{History: []Streak{}}
+
+	today, err := GetCurrentDrinkingDay(s)
+	if err != nil {
+		return summary, err
+	}
+
+	days, err := GetDrinkingDayRecords(s, firstTrackedDate, today)
+	if err != nil {
+		return summary, err
+	}
+	if len(days) == 0 {
+		return summary, nil
+	}
+
+	first := today
+	for day := range days {
+		if day.Before(first) {
+			first = day
+		}
+	}
+
+	summary.History = buildStreaks(first, today, func(d Date) bool {
+		return len(days[d]) > 0
+	})
+
+	for _, streak := range summary.History {
+		switch streak.Kind {
+		case SoberStreak:
+			if streak.Days > summary.LongestSober.Days {
+				summary.LongestSober = streak
+			}
+		case DrinkingStreak:
+			if streak.Days > summary.LongestDrinking.Days {
+				summary.LongestDrinking = streak
+			}
+		}
+	}
+
+	current := summary.History[len(summary.History)-1]
+	if current.Kind == SoberStreak {
+		summary.CurrentSober = current.Days
+	} else {
+		summary.CurrentDrinking = current.Days
+	}
+	return summary, nil
+}
+
+// buildStreaks splits the days from first through last into runs of drinking and
+// alcohol-free days; the final run is marked as current
+func buildStreaks(first, last Date, drank func(Date) bool) []Streak {
+	streaks := []Streak{}
+	for t := first.Time(); !last.Before(DateOf(t)); t = t.AddDate(0, 0, 1) {
+		day := DateOf(t)
+		kind := SoberStreak
+		if drank(day) {
+			kind = DrinkingStreak
+		}
+
+		if n := len(streaks); n > 0 && streaks[n-1].Kind == kind {
+			streaks[n-1].End = day
+			streaks[n-1].Days++
+			continue
+		}
+		streaks = append(streaks, Streak{Kind: kind, Start: day, End: day, Days: 1})
+	}
+
+	if n := len(streaks); n > 0 {
+		streaks[n-1].Current = true
+	}
+	return streaks
+}
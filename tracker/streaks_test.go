@@ -0,0 +1,108 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// daysBefore returns the date n days before d
+func daysBefore(d Date, n int) Date {
+	return DateOf(d.Time().AddDate(0, 0, -n))
+}
+
+// addAtNoon logs a beer at noon on a date, well clear of the rollover hour
+func addAtNoon(t *testing.T, s Store, d Date) DayData {
+	t.Helper()
+	return mustAdd(t, s, d, DayData{Alcohol: "Beer", Quantity: 355,
+		ConsumedAt: time.Date(d.Year, time.Month(d.Month), d.Day, 12, 0, 0, 0, time.UTC)})
+}
+
+// streakPattern renders a history as e.g. "D5 S2", for drinking and sober runs
+func streakPattern(history []Streak) string {
+	runs := []string{}
+	for _, streak := range history {
+		kind := "S"
+		if streak.Kind == DrinkingStreak {
+			kind = "D"
+		}
+		runs = append(runs, fmt.Sprintf("%s%d", kind, streak.Days))
+	}
+	return strings.Join(runs, " ")
+}
+
+func TestStreaksFollowInsertsAndDeletes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+		today, err := GetCurrentDrinkingDay(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Five drinking days, then five alcohol-free ones up to today
+		entries := map[int]DayData{}
+		for n := 9; n >= 5; n-- {
+			entries[n] = addAtNoon(t, s, daysBefore(today, n))
+		}
+
+		check := func(pattern string, currentSober, currentDrinking int, longestSober, longestDrinking Streak) {
+			t.Helper()
+			summary, err := GetStreaks(s)
+			if err != nil {
+				t.Fatalf("GetStreaks: %v", err)
+			}
+			if got := streakPattern(summary.History); got != pattern {
+				t.Errorf("history = %s, want %s", got, pattern)
+			}
+			if last := summary.History[len(summary.History)-1]; !last.Current || last.End != today {
+				t.Errorf("the last streak %+v is not the current one", last)
+			}
+			if summary.CurrentSober != currentSober || summary.CurrentDrinking != currentDrinking {
+				t.Errorf("current = %d sober, %d drinking; want %d and %d",
+					summary.CurrentSober, summary.CurrentDrinking, currentSober, currentDrinking)
+			}
+			if got := summary.LongestSober; got.Start != longestSober.Start || got.Days != longestSober.Days {
+				t.Errorf("longest sober = %d days from %s, want %d from %s", got.Days, got.Start, longestSober.Days, longestSober.Start)
+			}
+			if got := summary.LongestDrinking; got.Start != longestDrinking.Start || got.Days != longestDrinking.Days {
+				t.Errorf("longest drinking = %d days from %s, want %d from %s", got.Days, got.Start, longestDrinking.Days, longestDrinking.Start)
+			}
+		}
+		check("D5 S5", 5, 0,
+			Streak{Start: daysBefore(today, 4), Days: 5},
+			Streak{Start: daysBefore(today, 9), Days: 5})
+
+		// A drink in the middle of the sober run splits it
+		late := addAtNoon(t, s, daysBefore(today, 2))
+		check("D5 S2 D1 S2", 2, 0,
+			Streak{Start: daysBefore(today, 4), Days: 2},
+			Streak{Start: daysBefore(today, 9), Days: 5})
+
+		// Deleting one from the middle of the drinking run splits that too
+		middle := daysBefore(today, 7)
+		if err := s.DeleteEntry(middle.Year, middle.Month, middle.Day, entries[7].ID); err != nil {
+			t.Fatalf("DeleteEntry: %v", err)
+		}
+		check("D2 S1 D2 S2 D1 S2", 2, 0,
+			Streak{Start: daysBefore(today, 4), Days: 2},
+			Streak{Start: daysBefore(today, 9), Days: 2})
+
+		// Deleting the late drink joins the sober days up again
+		lateDate := daysBefore(today, 2)
+		if err := s.DeleteEntry(lateDate.Year, lateDate.Month, lateDate.Day, late.ID); err != nil {
+			t.Fatalf("DeleteEntry: %v", err)
+		}
+		check("D2 S1 D2 S5", 5, 0,
+			Streak{Start: daysBefore(today, 4), Days: 5},
+			Streak{Start: daysBefore(today, 9), Days: 2})
+
+		// A drink today ends the sober streak
+		addAtNoon(t, s, today)
+		check("D2 S1 D2 S4 D1", 0, 1,
+			Streak{Start: daysBefore(today, 4), Days: 4},
+			Streak{Start: daysBefore(today, 9), Days: 2})
+	})
+}
@@ -0,0 +1,114 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tier is a consumption level a day falls into once it reaches MinGrams of alcohol
+type Tier struct {
+	Name     string  `json:"name"`
+	MinGrams float64 `json:"min_grams"`
+	Color    string  `json:"color"`
+}
+
+// TierResult classifies a single day
+type TierResult struct {
+	Name      string  `json:"name"`
+	Index     int     `json:"index"` // 0 for a day without drinks, then 1..TierCount
+	TierCount int     `json:"tier_count"`
+	Color     string  `json:"color"`
+	Drinks    float64 `json:"drinks"` // Standard drinks under the selected definition
+}
+
+// noDrinksTier is used for days without any entries
+var noDrinksTier = Tier{Name: "empty", Color: "gray"}
+
+// Tiers used until the user saves their own, at 1, 2, 3 and 5 US standard drinks
+var defaultTiers = []Tier{
+	{Name: "low", MinGrams: 0, Color: "#60aa9b"},
+	{Name: "moderate", MinGrams: 14, Color: "#43766c"},
+	{Name: "heavy", MinGrams: 28, Color: "#ffdf60"},
+	{Name: "binge", MinGrams: 42, Color: "#fa8072"},
+	{Name: "excessive", MinGrams: 70, Color: "#ed4d09"},
+}
+
+// GetTiers returns the saved tiers, or the defaults if none were saved
+func GetTiers(s Store) ([]Tier, error) {
+	tiers := []Tier{}
+	found, err := loadSetting(s, tiersKey, &tiers)
+	if err != nil || !found {
+		return append([]Tier(nil), defaultTiers...), err
+	}
+	return tiers, nil
+}
+
+// SaveTiers validates and stores the tiers. They must start at 0 grams and
+// have strictly increasing boundaries.
+func SaveTiers(s Store, tiers []Tier) error {
+	if len(tiers) == 0 {
+		return errors.New("at least one tier is required")
+	}
+	if tiers[0].MinGrams != 0 {
+		return errors.New("the first tier must start at 0 grams")
+	}
+
+	for i := range tiers {
+		tiers[i].Name = strings.TrimSpace(tiers[i].Name)
+		if tiers[i].Name == "" {
+			return fmt.Errorf("tier %d has no name", i+1)
+		}
+		if i > 0 && tiers[i].MinGrams <= tiers[i-1].MinGrams {
+			return fmt.Errorf("tier '%s' must start above tier '%s'", tiers[i].Name, tiers[i-1].Name)
+		}
+	}
+	return saveSetting(s, tiersKey, tiers)
+}
+
+// ResetTiers restores the default tiers
+func ResetTiers(s Store) error {
+	return saveSetting(s, tiersKey, defaultTiers)
+}
+
+// classify finds the tier for a number of grams; found is false for a day without drinks
+func classify(tiers []Tier, grams float64, found bool) (Tier, int) {
+	if !found {
+		return noDrinksTier, 0
+	}
+
+	index := 1
+	for i, tier := range tiers {
+		if grams >= tier.MinGrams {
+			index = i + 1
+		}
+	}
+	return tiers[index-1], index
+}
+
+// ClassifyDay returns the tier, color and standard drink count for a day
+func ClassifyDay(s Store, year, month, day int) (TierResult, error) {
+	tiers, err := GetTiers(s)
+	if err != nil {
+		return TierResult{}, err
+	}
+
+	def, err := GetStandardDrink(s)
+	if err != nil {
+		return TierResult{}, err
+	}
+
+	grams, found, err := getTotalGramsOnDay(s, year, month, day)
+	if err != nil {
+		return TierResult{}, err
+	}
+
+	tier, index := classify(tiers, grams, found)
+	return TierResult{
+		Name:      tier.Name,
+		Index:     index,
+		TierCount: len(tiers),
+		Color:     tier.Color,
+		Drinks:    StandardDrinksFromGrams(grams, def),
+	}, nil
+}
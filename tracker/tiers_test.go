@@ -0,0 +1,95 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyBoundaries(t *testing.T) {
+	tests := []struct {
+		grams float64
+		found bool
+		want  string
+		index int
+	}{
+		{0, false, "empty", 0},
+		{0, true, "low", 1}, // Logged, but alcohol-free
+		{13.99, true, "low", 1},
+		{14, true, "moderate", 2},
+		{27.99, true, "moderate", 2},
+		{28, true, "heavy", 3},
+		{41.99, true, "heavy", 3},
+		{42, true, "binge", 4},
+		{69.99, true, "binge", 4},
+		{70, true, "excessive", 5},
+		{500, true, "excessive", 5},
+	}
+	for _, test := range tests {
+		if tier, index := classify(defaultTiers, test.grams, test.found); tier.Name != test.want || index != test.index {
+			t.Errorf("classify(%g, %t) = %s (%d), want %s (%d)", test.grams, test.found, tier.Name, index, test.want, test.index)
+		}
+	}
+}
+
+func TestClassifyDayWithSavedTiers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := SetTimezone(s, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetStandardDrink(s, "UK", 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveTiers(s, []Tier{{Name: " light ", MinGrams: 0, Color: "green"}, {Name: "over", MinGrams: 16, Color: "red"}}); err != nil {
+			t.Fatalf("SaveTiers: %v", err)
+		}
+
+		// 500 mL of 4% beer is 15.78 g; a second one crosses 16 g
+		at := time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)
+		mustAdd(t, s, Date{2024, 3, 5}, DayData{Alcohol: "Beer", Quantity: 500, ABV: 4, ConsumedAt: at})
+		tier, err := ClassifyDay(s, 2024, 3, 5)
+		if err != nil || tier.Name != "light" || tier.Index != 1 || tier.TierCount != 2 || tier.Color != "green" {
+			t.Errorf("one beer is %+v, %v; want light, 1 of 2", tier, err)
+		}
+		if want := AlcoholGrams(500, 4) / 8; tier.Drinks != want {
+			t.Errorf("one beer is %g UK units, want %g", tier.Drinks, want)
+		}
+
+		mustAdd(t, s, Date{2024, 3, 5}, DayData{Alcohol: "Beer", Quantity: 500, ABV: 4, ConsumedAt: at.Add(time.Hour)})
+		if tier, err := ClassifyDay(s, 2024, 3, 5); err != nil || tier.Name != "over" || tier.Index != 2 {
+			t.Errorf("two beers are %+v, %v; want over", tier, err)
+		}
+		if tier, err := ClassifyDay(s, 2024, 3, 6); err != nil || tier.Name != noDrinksTier.Name || tier.Index != 0 || tier.TierCount != 2 {
+			t.Errorf("a day without drinks is %+v, %v; want empty", tier, err)
+		}
+
+		if err := ResetTiers(s); err != nil {
+			t.Fatal(err)
+		}
+		if tier, err := ClassifyDay(s, 2024, 3, 5); err != nil || tier.Name != "heavy" || tier.TierCount != len(defaultTiers) {
+			t.Errorf("with the default tiers two beers are %+v, %v; want heavy", tier, err)
+		}
+	})
+}
+
+func TestSaveTiersValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiers []Tier
+	}{
+		{"no tiers", []Tier{}},
+		{"first above 0", []Tier{{Name: "low", MinGrams: 5}}},
+		{"unnamed", []Tier{{Name: "low"}, {Name: "  ", MinGrams: 14}}},
+		{"equal boundaries", []Tier{{Name: "low"}, {Name: "mid", MinGrams: 14}, {Name: "high", MinGrams: 14}}},
+		{"decreasing", []Tier{{Name: "low"}, {Name: "mid", MinGrams: 28}, {Name: "high", MinGrams: 14}}},
+	}
+
+	s := NewMemoryStore()
+	for _, test := range tests {
+		if err := SaveTiers(s, test.tiers); err == nil {
+			t.Errorf("%s: SaveTiers accepted %+v", test.name, test.tiers)
+		}
+	}
+	if tiers, err := GetTiers(s); err != nil || len(tiers) != len(defaultTiers) {
+		t.Errorf("after rejected saves the tiers are %+v, %v; want the defaults", tiers, err)
+	}
+}
@@ -0,0 +1,27 @@
+package tracker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GetAPIToken returns the token REST API clients authenticate with, creating
+// one the first time it is asked for
+func GetAPIToken(s Store) (string, error) {
+	token := ""
+	if _, err := loadSetting(s, apiTokenKey, &token); err != nil || token != "" {
+		return token, err
+	}
+	return ResetAPIToken(s)
+}
+
+// ResetAPIToken replaces the API token, locking out every client using the old one
+func ResetAPIToken(s Store) (string, error) {
+	var raw [24]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(raw[:])
+	return token, saveSetting(s, apiTokenKey, token)
+}
@@ -0,0 +1,83 @@
+package tracker
+
+import (
+	"fmt"
+)
+
+// VolumeUnit is a unit drinks can be logged and displayed in
+type VolumeUnit struct {
+	Code   string  `json:"code"`
+	Symbol string  `json:"symbol"`
+	ML     float64 `json:"ml"` // Milliliters in one unit
+}
+
+// Milliliters is the storage unit and the default display unit
+var Milliliters = VolumeUnit{Code: "ml", Symbol: "mL", ML: 1}
+
+// VolumeUnits lists the supported units
+var VolumeUnits = []VolumeUnit{
+	Milliliters,
+	{Code: "cl", Symbol: "cL", ML: 10},
+	{Code: "floz_us", Symbol: "fl oz (US)", ML: 29.5735},
+	{Code: "floz_uk", Symbol: "fl oz (UK)", ML: 28.4131},
+	{Code: "pint_us", Symbol: "pt (US)", ML: 473.176},
+	{Code: "pint_uk", Symbol: "pt (UK)", ML: 568.261},
+	{Code: "shot", Symbol: "shot", ML: 44},
+}
+
+// LookupVolumeUnit finds a unit by code
+func LookupVolumeUnit(code string) (VolumeUnit, error) {
+	for _, unit := range VolumeUnits {
+		if unit.Code == code {
+			return unit, nil
+		}
+	}
+	return VolumeUnit{}, fmt.Errorf("unknown volume unit '%s'", code)
+}
+
+// ToML converts an amount in unit to milliliters
+func (u VolumeUnit) ToML(amount float64) float64 {
+	return amount * u.ML
+}
+
+// FromML converts milliliters to an amount in unit
+func (u VolumeUnit) FromML(ml float64) float64 {
+	return ml / u.ML
+}
+
+// GetDisplayUnit returns the unit App quantities are shown in, defaulting to Milliliters
+func GetDisplayUnit(s Store) (VolumeUnit, error) {
+	code := Milliliters.Code
+	if _, err := loadSetting(s, displayUnitKey, &code); err != nil {
+		return Milliliters, err
+	}
+	return LookupVolumeUnit(code)
+}
+
+// SetDisplayUnit selects the unit App quantities are shown in
+func SetDisplayUnit(s Store, code string) error {
+	if _, err := LookupVolumeUnit(code); err != nil {
+		return err
+	}
+	return saveSetting(s, displayUnitKey, code)
+}
+
+// EntriesInUnit returns copies of entries with Quantity converted from mL to unit
+func EntriesInUnit(entries []DayData, unit VolumeUnit) []DayData {
+	converted := make([]DayData, len(entries))
+	for i, entry := range entries {
+		entry.Quantity = unit.FromML(entry.Quantity)
+		converted[i] = entry
+	}
+	return converted
+}
+
+// RecordsInUnit returns copies of records with Quantity converted from mL to unit
+func RecordsInUnit(records []Record, unit VolumeUnit) []Record {
+	converted := make([]Record, len(records))
+	for i, record := range records {
+		record.Entry.Quantity = unit.FromML(record.Entry.Quantity)
+		converted[i] = record
+	}
+	return converted
+}
@@ -0,0 +1,96 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVolumeUnitConversion(t *testing.T) {
+	tests := []struct {
+		code   string
+		amount float64
+		ml     float64
+	}{
+		{"ml", 355, 355},
+		{"cl", 33, 330},
+		{"cl", 2.5, 25},
+		{"floz_us", 12, 354.882},
+		{"floz_uk", 12, 340.9572},
+		{"floz_us", 16, 473.176}, // A US pint
+		{"floz_uk", 20, 568.262}, // A UK pint, to rounding
+		{"pint_us", 1, 473.176},
+		{"pint_uk", 1, 568.261},
+		{"pint_uk", 0.5, 284.1305},
+		{"shot", 1, 44},
+		{"shot", 1.5, 66},
+	}
+	for _, test := range tests {
+		unit, err := LookupVolumeUnit(test.code)
+		if err != nil {
+			t.Fatalf("LookupVolumeUnit(%s): %v", test.code, err)
+		}
+		if ml := unit.ToML(test.amount); math.Abs(ml-test.ml) > 1e-9 {
+			t.Errorf("%g %s = %g mL, want %g", test.amount, unit.Symbol, ml, test.ml)
+		}
+		if back := unit.FromML(unit.ToML(test.amount)); math.Abs(back-test.amount) > 1e-9 {
+			t.Errorf("%g %s came back from mL as %g", test.amount, unit.Symbol, back)
+		}
+	}
+
+	for _, code := range []string{"", "ML", "floz", "pint", "gallon"} {
+		if _, err := LookupVolumeUnit(code); err == nil {
+			t.Errorf("LookupVolumeUnit(%q) found a unit", code)
+		}
+	}
+}
+
+func TestQuantityInML(t *testing.T) {
+	if ml, err := quantityInML(1, "pint_uk"); err != nil || ml != 568.261 {
+		t.Errorf("a UK pint is %g mL, %v; want 568.261", ml, err)
+	}
+	if ml, err := quantityInML(330, ""); err != nil || ml != 330 {
+		t.Errorf("330 without a unit is %g mL, %v; want 330", ml, err)
+	}
+	for _, quantity := range []float64{0, -1} {
+		if _, err := quantityInML(quantity, "ml"); err == nil {
+			t.Errorf("a quantity of %g was accepted", quantity)
+		}
+	}
+	if _, err := quantityInML(1, "gallon"); err == nil {
+		t.Error("an unknown unit was accepted")
+	}
+}
+
+func TestDisplayUnitRoundTrip(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if unit, err := GetDisplayUnit(s); err != nil || unit != Milliliters {
+			t.Errorf("the default display unit is %+v, %v; want mL", unit, err)
+		}
+
+		for _, unit := range VolumeUnits {
+			if err := SetDisplayUnit(s, unit.Code); err != nil {
+				t.Fatalf("SetDisplayUnit(%s): %v", unit.Code, err)
+			}
+			if got, err := GetDisplayUnit(s); err != nil || got != unit {
+				t.Errorf("after selecting %s the display unit is %+v, %v", unit.Code, got, err)
+			}
+		}
+		if err := SetDisplayUnit(s, "gallon"); err == nil {
+			t.Error("SetDisplayUnit accepted an unknown unit")
+		}
+		if unit, err := GetDisplayUnit(s); err != nil || unit.Code != "shot" {
+			t.Errorf("after a rejected change the display unit is %+v, %v; want shot", unit, err)
+		}
+
+		// Entries are stored in mL and only converted for display
+		entry := mustAdd(t, s, Date{2024, 3, 5}, DayData{Alcohol: "Beer", Quantity: 568.261})
+		pint, _ := LookupVolumeUnit("pint_uk")
+		if shown := EntriesInUnit([]DayData{entry}, pint); shown[0].Quantity != 1 || entry.Quantity != 568.261 {
+			t.Errorf("shown as %g pints with %g mL stored, want 1 and 568.261", shown[0].Quantity, entry.Quantity)
+		}
+		records := RecordsInUnit([]Record{{Category: "Beer", Entry: entry}}, pint)
+		if records[0].Entry.Quantity != 1 {
+			t.Errorf("the record shows %g pints, want 1", records[0].Entry.Quantity)
+		}
+	})
+}